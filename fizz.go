@@ -1,22 +1,40 @@
 package fizz
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"path"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ccfish86/fizz/v2/openapi"
 	"github.com/ccfish86/gadgeto/tonic"
 	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v2"
 )
 
-const ctxOpenAPIOperation = "_ctx_openapi_operation"
+const (
+	ctxOpenAPIOperation = "_ctx_openapi_operation"
+	ctxBindHook         = "_ctx_fizz_bind_hook"
+	ctxRenderHook       = "_ctx_fizz_render_hook"
+	multipartFormData   = "multipart/form-data"
+)
+
+var tofFileHeader = reflect.TypeOf(multipart.FileHeader{})
 
 // Primitive type helpers.
 var (
@@ -31,6 +49,8 @@ var (
 	DateTime time.Time
 )
 
+var hooksOnce sync.Once
+
 // Fizz is an abstraction of a Gin engine that wraps the
 // routes handlers with Tonic and generates an OpenAPI
 // 3.0 specification from it.
@@ -44,10 +64,24 @@ type Fizz struct {
 type RouterGroup struct {
 	group       *gin.RouterGroup
 	gen         *openapi.Generator
+	rev         *uint64
+	defaults    *routeDefaults
+	parent      *RouterGroup
+	security    []*openapi.SecurityRequirement
+	deprecated  bool
 	Name        string
 	Description string
 }
 
+// routeDefaults represents the defaults applied to
+// the Tonic-wrapped handlers registered with Fizz.
+type routeDefaults struct {
+	bindHook          tonic.BindHook
+	renderHook        tonic.RenderHook
+	requestMediaType  string
+	responseMediaType string
+}
+
 // New creates a new Fizz wrapper for
 // a default Gin engine.
 func New() *Fizz {
@@ -57,25 +91,55 @@ func New() *Fizz {
 // NewFromEngine creates a new Fizz wrapper
 // from an existing Gin engine.
 func NewFromEngine(e *gin.Engine) *Fizz {
+	return NewFromEngineWithConfig(e, nil)
+}
+
+// NewFromEngineWithConfig creates a new Fizz wrapper from
+// an existing Gin engine, with a spec generator that uses
+// the tags of the given config. The empty tags of the config
+// are set to the tags used by Tonic.
+func NewFromEngineWithConfig(e *gin.Engine, cfg *openapi.SpecGenConfig) *Fizz {
 	// Create a new spec with the config
 	// based on tonic internals.
-	gen, _ := openapi.NewGenerator(
-		&openapi.SpecGenConfig{
-			ValidatorTag:      tonic.ValidationTag,
-			PathLocationTag:   tonic.PathTag,
-			QueryLocationTag:  tonic.QueryTag,
-			FormLocationTag:   "form",
-			HeaderLocationTag: tonic.HeaderTag,
-			EnumTag:           tonic.EnumTag,
-			DefaultTag:        tonic.DefaultTag,
-		},
-	)
+	conf := openapi.SpecGenConfig{
+		ValidatorTag:      tonic.ValidationTag,
+		PathLocationTag:   tonic.PathTag,
+		QueryLocationTag:  tonic.QueryTag,
+		FormLocationTag:   "form",
+		HeaderLocationTag: tonic.HeaderTag,
+		CookieLocationTag: "cookie",
+		EnumTag:           tonic.EnumTag,
+		DefaultTag:        tonic.DefaultTag,
+	}
+	if cfg != nil {
+		for _, f := range []struct {
+			dst *string
+			src string
+		}{
+			{&conf.ValidatorTag, cfg.ValidatorTag},
+			{&conf.PathLocationTag, cfg.PathLocationTag},
+			{&conf.QueryLocationTag, cfg.QueryLocationTag},
+			{&conf.FormLocationTag, cfg.FormLocationTag},
+			{&conf.HeaderLocationTag, cfg.HeaderLocationTag},
+			{&conf.CookieLocationTag, cfg.CookieLocationTag},
+			{&conf.EnumTag, cfg.EnumTag},
+			{&conf.DefaultTag, cfg.DefaultTag},
+		} {
+			if f.src != "" {
+				*f.dst = f.src
+			}
+		}
+	}
+	gen, _ := openapi.NewGenerator(&conf)
+
 	return &Fizz{
 		engine: e,
 		gen:    gen,
 		RouterGroup: &RouterGroup{
-			group: &e.RouterGroup,
-			gen:   gen,
+			group:    &e.RouterGroup,
+			gen:      gen,
+			rev:      new(uint64),
+			defaults: &routeDefaults{},
 		},
 	}
 }
@@ -106,6 +170,63 @@ func (f *Fizz) Errors() []error {
 	return f.gen.Errors()
 }
 
+// SetDefaultBindHook sets the bind hook of the Tonic-wrapped
+// handlers registered afterward that don't set their own.
+// Note that the hook relies on the bind hook of Tonic, which
+// must not be replaced afterward.
+func (f *Fizz) SetDefaultBindHook(h tonic.BindHook) {
+	installHooks()
+	f.defaults.bindHook = h
+}
+
+// SetDefaultRenderHook sets the render hook of the Tonic-wrapped
+// handlers registered afterward that don't set their own.
+// Note that the hook relies on the render hook of Tonic, which
+// must not be replaced afterward.
+func (f *Fizz) SetDefaultRenderHook(h tonic.RenderHook) {
+	installHooks()
+	f.defaults.renderHook = h
+}
+
+// SetDefaultRequestMediaType sets the media type of the
+// request bodies of the Tonic-wrapped handlers registered
+// afterward that don't set their own, such as the media
+// type read by the default bind hook.
+func (f *Fizz) SetDefaultRequestMediaType(mt string) {
+	f.defaults.requestMediaType = mt
+}
+
+// SetDefaultResponseMediaType sets the media type of the
+// response bodies of the Tonic-wrapped handlers registered
+// afterward that don't set their own, such as the media
+// type written by the default render hook.
+func (f *Fizz) SetDefaultResponseMediaType(mt string) {
+	f.defaults.responseMediaType = mt
+}
+
+// installHooks replaces the bind and render hooks of Tonic
+// with hooks that call the default hooks of the route set
+// in the Gin context, if any, or the previous hooks.
+func installHooks() {
+	hooksOnce.Do(func() {
+		bh, rh := tonic.GetBindHook(), tonic.GetRenderHook()
+
+		tonic.SetBindHook(func(c *gin.Context, v interface{}) error {
+			if h, ok := c.Get(ctxBindHook); ok {
+				return h.(tonic.BindHook)(c, v)
+			}
+			return bh(c, v)
+		})
+		tonic.SetRenderHook(func(c *gin.Context, statusCode int, payload interface{}) {
+			if h, ok := c.Get(ctxRenderHook); ok {
+				h.(tonic.RenderHook)(c, statusCode, payload)
+				return
+			}
+			rh(c, statusCode, payload)
+		}, "")
+	})
+}
+
 // Group creates a new group of routes.
 func (g *RouterGroup) Group(path, name, description string, handlers ...gin.HandlerFunc) *RouterGroup {
 	// Create the tag in the specification
@@ -114,12 +235,74 @@ func (g *RouterGroup) Group(path, name, description string, handlers ...gin.Hand
 
 	return &RouterGroup{
 		gen:         g.gen,
+		rev:         g.rev,
+		defaults:    g.defaults,
 		group:       g.group.Group(path, handlers...),
+		parent:      g,
 		Name:        name,
 		Description: description,
 	}
 }
 
+// Security adds a security requirement, that references the
+// security scheme with the given name and scopes, to all the
+// operations registered on the group and its subgroups. The
+// requirements of a subgroup replace the ones of its parent,
+// and an operation can override them with its own options.
+// Note that this method can be used more than once to add
+// several requirements.
+func (g *RouterGroup) Security(name string, scopes ...string) *RouterGroup {
+	if scopes == nil {
+		scopes = []string{}
+	}
+	g.security = append(g.security, &openapi.SecurityRequirement{
+		name: scopes,
+	})
+	return g
+}
+
+// Deprecated marks all the operations registered on the
+// group and its subgroups as deprecated. An operation can
+// override it with the Deprecated(false) option.
+func (g *RouterGroup) Deprecated() *RouterGroup {
+	g.deprecated = true
+	return g
+}
+
+// isDeprecated returns whether the group
+// or one of its parents is deprecated.
+func (g *RouterGroup) isDeprecated() bool {
+	for rg := g; rg != nil; rg = rg.parent {
+		if rg.deprecated {
+			return true
+		}
+	}
+	return false
+}
+
+// parentTags returns the names of the ancestors of
+// the group, from the outermost to the nearest one.
+func (g *RouterGroup) parentTags() []string {
+	var tags []string
+	for rg := g.parent; rg != nil; rg = rg.parent {
+		if rg.Name != "" {
+			tags = append([]string{rg.Name}, tags...)
+		}
+	}
+	return tags
+}
+
+// securityRequirements returns the default security
+// requirements of the operations of the group.
+func (g *RouterGroup) securityRequirements() []*openapi.SecurityRequirement {
+	for rg := g; rg != nil; rg = rg.parent {
+		if rg.security != nil {
+			return rg.security
+		}
+	}
+	return nil
+}
+
 // Use adds middleware to the group.
 func (g *RouterGroup) Use(handlers ...gin.HandlerFunc) {
 	g.group.Use(handlers...)
@@ -173,21 +356,35 @@ func (g *RouterGroup) TRACE(path string, infos []OperationOption, handlers ...gi
 // Handle registers a new request handler that is wrapped
 // with Tonic and documented in the OpenAPI specification.
 func (g *RouterGroup) Handle(path, method string, infos []OperationOption, handlers ...gin.HandlerFunc) *RouterGroup {
-	oi := &openapi.OperationInfo{}
+	oi := &openapi.OperationInfo{
+		Deprecated: g.isDeprecated(),
+	}
 	for _, info := range infos {
 		info(oi)
 	}
+	// Tag the operation with the names of the parent
+	// groups, in addition to the name of the group.
+	oi.Tags = append(g.parentTags(), oi.Tags...)
+
+	// Apply the security requirements of the group
+	// unless the operation defines its own.
+	if oi.Security == nil {
+		if sr := g.securityRequirements(); sr != nil {
+			oi.Security = append([]*openapi.SecurityRequirement{}, sr...)
+		}
+	}
 	type wrap struct {
+		i int
 		h gin.HandlerFunc
 		r *tonic.Route
 	}
 	var wrapped []wrap
 
 	// Find the handlers wrapped with Tonic.
-	for _, h := range handlers {
+	for i, h := range handlers {
 		r, err := tonic.GetRouteByHandler(h)
 		if err == nil {
-			wrapped = append(wrapped, wrap{h: h, r: r})
+			wrapped = append(wrapped, wrap{i: i, h: h, r: r})
 		}
 	}
 	// Check that no more that one tonic-wrapped handler
@@ -196,8 +393,8 @@ func (g *RouterGroup) Handle(path, method string, infos []OperationOption, handl
 		panic(fmt.Sprintf("multiple tonic-wrapped handler used for operation %s %s", method, path))
 	}
 	// If we have a tonic-wrapped handler, generate the
-	// specification of this operation.
-	if len(wrapped) == 1 {
+	// specification of this operation, unless it is hidden.
+	if len(wrapped) == 1 && !oi.Hidden {
 		hfunc := wrapped[0].r
 
 		// Set an operation ID if none is provided.
@@ -205,20 +402,40 @@ func (g *RouterGroup) Handle(path, method string, infos []OperationOption, handl
 			oi.ID = hfunc.HandlerName()
 		}
 		oi.StatusCode = hfunc.GetDefaultStatusCode()
+
+		// Set an input type if provided.
+		it := hfunc.InputType()
+		if oi.InputModel != nil {
+			it = reflect.TypeOf(oi.InputModel)
+		}
 		requestMediaType := hfunc.GetRequestMediaType()
 		if requestMediaType == "" {
-			requestMediaType = tonic.MediaType()
+			requestMediaType = g.defaults.requestMediaType
+		}
+		if requestMediaType == "" {
+			// Files can only be uploaded with
+			// a multipart form request.
+			if hasFileField(it) {
+				requestMediaType = multipartFormData
+			} else {
+				requestMediaType = g.gen.DefaultContentType()
+			}
 		}
 		responseMediaType := hfunc.GetResponseMediaType()
 		if responseMediaType == "" {
-			responseMediaType = tonic.MediaType()
+			responseMediaType = g.defaults.responseMediaType
 		}
-		// Set an input type if provided.
-		it := hfunc.InputType()
-		if oi.InputModel != nil {
-			it = reflect.TypeOf(oi.InputModel)
+		if responseMediaType == "" {
+			responseMediaType = g.gen.DefaultContentType()
 		}
 
+		// Register the additional tags of the operation
+		// that are not declared yet, without description.
+		for _, name := range oi.Tags {
+			if _, ok := g.gen.TagByName(name); !ok {
+				g.gen.AddTag(name, "")
+			}
+		}
 		// Consolidate path for OpenAPI spec.
 		operationPath := joinPaths(g.group.BasePath(), path)
 
@@ -230,6 +447,8 @@ func (g *RouterGroup) Handle(path, method string, infos []OperationOption, handl
 				method, path, err,
 			))
 		}
+		// Invalidate the cached specifications.
+		atomic.AddUint64(g.rev, 1)
 		// If an operation was generated for the handler,
 		// wrap the Tonic-wrapped handled with a closure
 		// to inject it into the Gin context.
@@ -245,32 +464,303 @@ func (g *RouterGroup) Handle(path, method string, infos []OperationOption, handl
 			}
 		}
 	}
+	// Inject the default hooks into the Gin context
+	// of the Tonic-wrapped handler that doesn't set
+	// its own hooks.
+	if len(wrapped) == 1 {
+		bh, rh := g.defaults.bindHook, g.defaults.renderHook
+		if wrapped[0].r.GetBindHook() != nil {
+			bh = nil
+		}
+		if wrapped[0].r.GetRenderHook() != nil {
+			rh = nil
+		}
+		if bh != nil || rh != nil {
+			orig := handlers[wrapped[0].i]
+			handlers[wrapped[0].i] = func(c *gin.Context) {
+				if bh != nil {
+					c.Set(ctxBindHook, bh)
+				}
+				if rh != nil {
+					c.Set(ctxRenderHook, rh)
+				}
+				orig(c)
+			}
+		}
+	}
 	// Register the handlers with Gin underlying group.
 	g.group.Handle(method, path, handlers...)
 
 	return g
 }
 
+// OpenAPIOption represents an option-pattern function
+// used to configure the handler of the specification.
+type OpenAPIOption func(*openAPIConfig)
+
+type openAPIConfig struct {
+	cache         bool
+	dynamicServer bool
+}
+
+// WithCache enables the caching of the marshalled
+// specification. The spec is marshalled once and served
+// with an ETag header until a new operation is registered.
+// Note that the changes made with the generator after the
+// spec has been served are not reflected until then.
+func WithCache(enabled bool) OpenAPIOption {
+	return func(c *openAPIConfig) {
+		c.cache = enabled
+	}
+}
+
+// WithDynamicServer replaces the URL of the first server
+// of the specification with the scheme and the host of the
+// request, to serve the same API under several hostnames.
+// The path of the URL of the static server, if any, is kept
+// as the base path. Since the document depends on the request,
+// the caching enabled with WithCache is disabled.
+func WithDynamicServer(enabled bool) OpenAPIOption {
+	return func(c *openAPIConfig) {
+		c.dynamicServer = enabled
+	}
+}
+
 // OpenAPI returns a Gin HandlerFunc that serves
-// the marshalled OpenAPI specification of the API.
-func (f *Fizz) OpenAPI(info *openapi.Info, ct string) gin.HandlerFunc {
+// the marshalled OpenAPI specification of the API,
+// compressed with gzip if the client accepts it.
+func (f *Fizz) OpenAPI(info *openapi.Info, ct string, opts ...OpenAPIOption) gin.HandlerFunc {
 	f.gen.SetInfo(info)
 
+	cfg := &openAPIConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
 	ct = strings.ToLower(ct)
 	if ct == "" {
 		ct = "json"
 	}
+	if cfg.dynamicServer {
+		cfg.cache = false
+	}
+	spec := func(c *gin.Context) *openapi.OpenAPI {
+		api := f.gen.API()
+		if cfg.dynamicServer {
+			api.Servers = dynamicServers(c.Request, api.Servers)
+		}
+		return api
+	}
+	var (
+		mime    string
+		marshal func(interface{}) ([]byte, error)
+	)
 	switch ct {
 	case "json":
-		return func(c *gin.Context) {
-			c.JSON(200, f.gen.API())
-		}
+		mime, marshal = "application/json; charset=utf-8", json.Marshal
 	case "yaml":
-		return func(c *gin.Context) {
-			c.YAML(200, f.gen.API())
+		mime, marshal = "application/x-yaml; charset=utf-8", yaml.Marshal
+	default:
+		panic("invalid content type, use JSON or YAML")
+	}
+	if cfg.cache {
+		return f.cachedOpenAPI(mime, marshal)
+	}
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Encoding")
+
+		if acceptsGzip(c.Request) {
+			b, err := marshal(spec(c))
+			if err == nil {
+				b, err = gzipBytes(b)
+			}
+			if err != nil {
+				_ = c.AbortWithError(http.StatusInternalServerError, err)
+				return
+			}
+			c.Header("Content-Encoding", "gzip")
+			c.Data(http.StatusOK, mime, b)
+			return
+		}
+		if ct == "json" {
+			c.JSON(http.StatusOK, spec(c))
+		} else {
+			c.YAML(http.StatusOK, spec(c))
+		}
+	}
+}
+
+// dynamicServers returns a copy of the servers whose
+// first URL is computed from the scheme and the host
+// of the request.
+func dynamicServers(r *http.Request, servers []*openapi.Server) []*openapi.Server {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+	host := r.Host
+	if fh := r.Header.Get("X-Forwarded-Host"); fh != "" {
+		host = strings.TrimSpace(strings.Split(fh, ",")[0])
+	}
+	server := &openapi.Server{}
+	if len(servers) != 0 && servers[0] != nil {
+		cpy := *servers[0]
+		server = &cpy
+	}
+	var basePath string
+	if u, err := url.Parse(server.URL); err == nil {
+		basePath = strings.TrimSuffix(u.Path, "/")
+	}
+	server.URL = scheme + "://" + host + basePath
+	server.Variables = nil
+
+	ret := []*openapi.Server{server}
+	if len(servers) > 1 {
+		ret = append(ret, servers[1:]...)
+	}
+	return ret
+}
+
+// cachedOpenAPI returns a Gin HandlerFunc that serves the
+// specification marshalled with the given function, which
+// is cached until a new operation is registered.
+func (f *Fizz) cachedOpenAPI(ct string, marshal func(interface{}) ([]byte, error)) gin.HandlerFunc {
+	var (
+		mu     sync.Mutex
+		rev    uint64
+		body   []byte
+		gzBody []byte
+		etag   string
+	)
+	return func(c *gin.Context) {
+		gz := acceptsGzip(c.Request)
+
+		mu.Lock()
+		if r := atomic.LoadUint64(f.rev); body == nil || r != rev {
+			b, err := marshal(f.gen.API())
+			if err != nil {
+				mu.Unlock()
+				_ = c.AbortWithError(http.StatusInternalServerError, err)
+				return
+			}
+			sum := sha1.Sum(b)
+			body, gzBody, rev, etag = b, nil, r, hex.EncodeToString(sum[:])
+		}
+		// The compressed document is cached alongside the
+		// plain one the first time that it is requested.
+		if gz && gzBody == nil {
+			b, err := gzipBytes(body)
+			if err != nil {
+				mu.Unlock()
+				_ = c.AbortWithError(http.StatusInternalServerError, err)
+				return
+			}
+			gzBody = b
+		}
+		b, tag := body, `"`+etag+`"`
+		if gz {
+			b, tag = gzBody, `"`+etag+`-gzip"`
+		}
+		mu.Unlock()
+
+		c.Header("ETag", tag)
+		c.Header("Vary", "Accept-Encoding")
+		if etagMatch(c.GetHeader("If-None-Match"), tag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		if gz {
+			c.Header("Content-Encoding", "gzip")
+		}
+		c.Data(http.StatusOK, ct, b)
+	}
+}
+
+// acceptsGzip returns whether the client accepts a
+// response compressed with gzip, according to the
+// Accept-Encoding headers of the request.
+func acceptsGzip(r *http.Request) bool {
+	for _, h := range r.Header.Values("Accept-Encoding") {
+		for _, v := range strings.Split(h, ",") {
+			parts := strings.Split(v, ";")
+			coding := strings.ToLower(strings.TrimSpace(parts[0]))
+			if coding != "gzip" && coding != "x-gzip" && coding != "*" {
+				continue
+			}
+			accepted := true
+			for _, p := range parts[1:] {
+				p = strings.ReplaceAll(p, " ", "")
+				if strings.HasPrefix(p, "q=") {
+					q, err := strconv.ParseFloat(p[2:], 64)
+					accepted = err == nil && q > 0
+				}
+			}
+			if accepted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// gzipBytes returns the data compressed with gzip.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// etagMatch returns whether the value of an
+// If-None-Match header matches the given ETag.
+func etagMatch(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
 		}
 	}
-	panic("invalid content type, use JSON or YAML")
+	return false
+}
+
+// ValidateRequestMiddleware returns a Gin middleware that
+// validates the parameters and the JSON body of the requests
+// against the schemas of the operations of the specification,
+// such as the required fields, the enums and the bounds. An
+// invalid request is aborted with a 400 status and the details
+// of the errors. The requests of the routes that don't have an
+// operation in the specification are not validated.
+func (f *Fizz) ValidateRequestMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op, ok := f.gen.Operation(c.Request.Method, c.FullPath())
+		if !ok {
+			return
+		}
+		params := make(map[string]string, len(c.Params))
+		for _, p := range c.Params {
+			params[p.Key] = p.Value
+		}
+		errs := f.gen.ValidateRequest(op, c.Request, params)
+		if len(errs) == 0 {
+			return
+		}
+		details := make([]string, 0, len(errs))
+		for _, err := range errs {
+			details = append(details, err.Error())
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request",
+			"details": details,
+		})
+	}
 }
 
 // OperationOption represents an option-pattern function
@@ -284,6 +774,18 @@ func StatusDescription(desc string) func(*openapi.OperationInfo) {
 	}
 }
 
+// StatusContent adds a media type, with its model, to the
+// default response of the operation, in addition to the
+// media type of the model returned by the handler.
+func StatusContent(mediaType string, model interface{}) func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		if o.StatusContents == nil {
+			o.StatusContents = make(map[string]interface{})
+		}
+		o.StatusContents[mediaType] = model
+	}
+}
+
 // Summary adds a summary to an operation.
 func Summary(summary string) func(*openapi.OperationInfo) {
 	return func(o *openapi.OperationInfo) {
@@ -321,6 +823,15 @@ func ID(id string) func(*openapi.OperationInfo) {
 	}
 }
 
+// Tags adds tags to the operation, in addition to the
+// names of its groups, such as cross-cutting tags. The
+// tags that don't exist are added to the specification.
+func Tags(names ...string) func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		o.Tags = append(o.Tags, names...)
+	}
+}
+
 // Deprecated marks the operation as deprecated.
 func Deprecated(deprecated bool) func(*openapi.OperationInfo) {
 	return func(o *openapi.OperationInfo) {
@@ -354,6 +865,51 @@ func ResponseWithExamples(statusCode, desc string, model interface{}, headers []
 	}
 }
 
+// ResponseWithContents is a variant of Response that accept a
+// model for each media type of the response content.
+func ResponseWithContents(statusCode, desc string, contents map[string]interface{}, headers []*openapi.ResponseHeader) func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		o.Responses = append(o.Responses, &openapi.OperationResponse{
+			Code:        statusCode,
+			Description: desc,
+			Headers:     headers,
+			Contents:    contents,
+		})
+	}
+}
+
+// Callback adds a callback to the operation. The path item
+// describes the request that is sent to the URL identified by
+// the runtime expression, such as {$request.body#/callbackUrl}.
+// Note that this function can be used more than once with the
+// same name to add several expressions to a callback.
+func Callback(name, expression string, item *openapi.PathItem) func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		if o.Callbacks == nil {
+			o.Callbacks = make(map[string]*openapi.Callback)
+		}
+		cb, ok := o.Callbacks[name]
+		if !ok {
+			cb = &openapi.Callback{}
+			o.Callbacks[name] = cb
+		}
+		(*cb)[expression] = item
+	}
+}
+
+// ResponseLink adds a link to the response of the operation
+// with the given status code. The link usually refers to
+// another operation by its ID.
+func ResponseLink(statusCode, name string, link *openapi.Link) func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		o.Links = append(o.Links, &openapi.OperationLink{
+			Code: statusCode,
+			Name: name,
+			Link: link,
+		})
+	}
+}
+
 // Header adds a header to the operation.
 func Header(name, desc string, model interface{}) func(*openapi.OperationInfo) {
 	return func(o *openapi.OperationInfo) {
@@ -372,6 +928,69 @@ func InputModel(model interface{}) func(*openapi.OperationInfo) {
 	}
 }
 
+// InputSchema sets the schema of the request body of the
+// operation, such as a hand-written schema of a dynamic JSON
+// body, instead of the one of the fields of the binding model.
+// It cannot be used with InputModel.
+func InputSchema(schema *openapi.Schema) func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		o.InputSchema = schema
+	}
+}
+
+// InputMediaType overrides the media type of the
+// request body of the operation.
+func InputMediaType(mediaType string) func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		o.InputMediaType = mediaType
+	}
+}
+
+// RequestBodyRequired sets whether the request body of the
+// operation is required. By default, the request body is not
+// marked as required, since an empty body is accepted by Tonic.
+func RequestBodyRequired(required bool) func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		o.BodyRequired = &required
+	}
+}
+
+// RequestExample adds a named example of the
+// request body to the operation.
+func RequestExample(name string, value interface{}) func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		if o.RequestExamples == nil {
+			o.RequestExamples = make(map[string]interface{})
+		}
+		o.RequestExamples[name] = value
+	}
+}
+
+// ResponseExample adds a named example to the response
+// of the operation with the given code, which can be the
+// code of the success response or of an additional one.
+func ResponseExample(code, name string, value interface{}) func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		if o.ResponseExamples == nil {
+			o.ResponseExamples = make(map[string]map[string]interface{})
+		}
+		if o.ResponseExamples[code] == nil {
+			o.ResponseExamples[code] = make(map[string]interface{})
+		}
+		o.ResponseExamples[code][name] = value
+	}
+}
+
+// ResponseExampleRef adds a named example to the response
+// of the operation with the given code, which references
+// the example registered in the components of the spec
+// with the name exampleRef.
+func ResponseExampleRef(code, name, exampleRef string) func(*openapi.OperationInfo) {
+	return ResponseExample(code, name, &openapi.Reference{
+		Ref: "#/components/examples/" + exampleRef,
+	})
+}
+
 // XCodeSample adds a code sample to the operation.
 func XCodeSample(cs *openapi.XCodeSample) func(*openapi.OperationInfo) {
 	return func(o *openapi.OperationInfo) {
@@ -379,6 +998,25 @@ func XCodeSample(cs *openapi.XCodeSample) func(*openapi.OperationInfo) {
 	}
 }
 
+// Servers sets the servers of the operation, which
+// override the servers of the specification, such as
+// for an endpoint served by a different backend.
+func Servers(servers ...*openapi.Server) func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		o.Servers = append(o.Servers, servers...)
+	}
+}
+
+// ExternalDocs sets the external documentation of the operation.
+func ExternalDocs(url, description string) func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		o.ExternalDocs = &openapi.ExternalDocumentation{
+			URL:         url,
+			Description: description,
+		}
+	}
+}
+
 // Overrides top-level security requirement for this operation.
 // Note that this function can be used more than once to add several requirements.
 func Security(security *openapi.SecurityRequirement) func(*openapi.OperationInfo) {
@@ -387,6 +1025,20 @@ func Security(security *openapi.SecurityRequirement) func(*openapi.OperationInfo
 	}
 }
 
+// WithSecurity adds a security requirement to the operation that
+// references the security scheme with the given name and scopes.
+// Note that this function can be used more than once to add several requirements.
+func WithSecurity(name string, scopes ...string) func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		if scopes == nil {
+			scopes = []string{}
+		}
+		o.Security = append(o.Security, &openapi.SecurityRequirement{
+			name: scopes,
+		})
+	}
+}
+
 // Add an empty security requirement to this operation to make other security requirements optional.
 func WithOptionalSecurity() func(*openapi.OperationInfo) {
 	return func(o *openapi.OperationInfo) {
@@ -409,6 +1061,25 @@ func XInternal() func(*openapi.OperationInfo) {
 	}
 }
 
+// Extension adds a specification extension, whose
+// name must start with x-, to the operation.
+func Extension(key string, value interface{}) func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		if o.Extensions == nil {
+			o.Extensions = make(map[string]interface{})
+		}
+		o.Extensions[key] = value
+	}
+}
+
+// Hidden excludes the operation from the specification.
+// The route is still registered and served normally.
+func Hidden() func(*openapi.OperationInfo) {
+	return func(o *openapi.OperationInfo) {
+		o.Hidden = true
+	}
+}
+
 // OperationFromContext returns the OpenAPI operation from
 // the given Gin context or an error if none is found.
 func OperationFromContext(ctx context.Context) (*openapi.Operation, error) {
@@ -449,3 +1120,32 @@ func funcEqual(f1, f2 interface{}) bool {
 	}
 	return false
 }
+
+// hasFileField returns whether the struct type t, or one
+// of its embedded structs, has a field of type FileHeader,
+// or a slice of them.
+func hasFileField(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i).Type
+		for ft.Kind() == reflect.Ptr || ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array {
+			ft = ft.Elem()
+		}
+		if ft == tofFileHeader {
+			return true
+		}
+		// Skip recursive embedding.
+		if t.Field(i).Anonymous && ft != t && hasFileField(ft) {
+			return true
+		}
+	}
+	return false
+}
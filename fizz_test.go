@@ -1,14 +1,17 @@
 package fizz
 
 import (
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -444,6 +447,925 @@ func TestSpecHandler(t *testing.T) {
 	}
 }
 
+// TestOperationInfoOptions tests that the summary, the
+// description and the ID options compose on a route.
+func TestOperationInfoOptions(t *testing.T) {
+	fizz := New()
+
+	fizz.GET("/fruits", []OperationOption{
+		Summary("List fruits"),
+		Description("Returns the fruits of the market."),
+		ID("ListFruits"),
+	}, tonic.Handler(func(c *gin.Context) error { return nil }, 200))
+
+	op, ok := fizz.Generator().Operation("GET", "/fruits")
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, "List fruits", op.Summary)
+	assert.Equal(t, "Returns the fruits of the market.", op.Description)
+	assert.Equal(t, "ListFruits", op.ID)
+}
+
+// TestRequestBodyRequired tests that the request
+// body of an operation can be marked as required.
+func TestRequestBodyRequired(t *testing.T) {
+	type In struct {
+		Name string `json:"name"`
+	}
+	fizz := New()
+	handler := tonic.Handler(func(c *gin.Context, in *In) error { return nil }, 200)
+
+	fizz.POST("/fruits", []OperationOption{ID("CreateFruit"), RequestBodyRequired(true)}, handler)
+	fizz.PATCH("/fruits", []OperationOption{ID("UpdateFruit"), RequestBodyRequired(false)}, handler)
+	fizz.PUT("/fruits", []OperationOption{ID("ReplaceFruit")}, handler)
+
+	assert.Panics(t, func() {
+		fizz.GET("/fruits", []OperationOption{ID("ListFruits"), RequestBodyRequired(true)},
+			tonic.Handler(func(c *gin.Context) error { return nil }, 200),
+		)
+	})
+	b, err := json.Marshal(fizz.Generator().API().Paths["/fruits"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var item map[string]struct {
+		RequestBody map[string]interface{} `json:"requestBody"`
+	}
+	if err := json.Unmarshal(b, &item); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, item["post"].RequestBody["required"])
+	assert.NotContains(t, item["patch"].RequestBody, "required")
+	assert.NotContains(t, item["put"].RequestBody, "required")
+}
+
+// TestSecurityRequirements tests that the security
+// requirements of the operations are marshaled and
+// can be unmarshaled back.
+func TestSecurityRequirements(t *testing.T) {
+	fizz := New()
+
+	err := fizz.Generator().AddSecurityScheme("bearer", &openapi.SecurityScheme{
+		Type:   "http",
+		Scheme: "bearer",
+	})
+	assert.Nil(t, err)
+	fizz.Generator().SetGlobalSecurity(&openapi.SecurityRequirement{"bearer": []string{}})
+
+	handler := tonic.Handler(func(c *gin.Context) error { return nil }, 200)
+
+	fizz.GET("/private", []OperationOption{
+		ID("Private"),
+		WithSecurity("oauth2", "read:pets", "write:pets"),
+	}, handler)
+	fizz.GET("/public", []OperationOption{
+		ID("Public"),
+		WithoutSecurity(),
+	}, handler)
+	fizz.GET("/default", []OperationOption{
+		ID("Default"),
+	}, handler)
+
+	b, err := json.Marshal(fizz.Generator().API())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var api openapi.OpenAPI
+	if err := json.Unmarshal(b, &api); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "bearer", api.Components.SecuritySchemes["bearer"].Scheme)
+	assert.Equal(t, []*openapi.SecurityRequirement{{"bearer": []string{}}}, api.Security)
+	assert.Equal(t,
+		[]*openapi.SecurityRequirement{{"oauth2": []string{"read:pets", "write:pets"}}},
+		api.Paths["/private"].GET.Security,
+	)
+	assert.NotNil(t, api.Paths["/public"].GET.Security)
+	assert.Empty(t, api.Paths["/public"].GET.Security)
+	assert.Nil(t, api.Paths["/default"].GET.Security)
+}
+
+// TestExternalDocs tests that the external documentation
+// of the spec and of an operation are marshaled.
+func TestExternalDocs(t *testing.T) {
+	fizz := New()
+	fizz.Generator().SetExternalDocs("https://example.com/docs", "Guides")
+
+	handler := tonic.Handler(func(c *gin.Context) error { return nil }, 200)
+
+	fizz.GET("/documented", []OperationOption{
+		ID("Documented"),
+		ExternalDocs("https://example.com/docs/documented", "Documented operation"),
+	}, handler)
+	fizz.GET("/undocumented", []OperationOption{
+		ID("Undocumented"),
+	}, handler)
+
+	b, err := json.Marshal(fizz.Generator().API())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var api struct {
+		ExternalDocs map[string]string `json:"externalDocs"`
+		Paths        map[string]map[string]struct {
+			ExternalDocs map[string]string `json:"externalDocs"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(b, &api); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]string{
+		"url":         "https://example.com/docs",
+		"description": "Guides",
+	}, api.ExternalDocs)
+	assert.Equal(t, map[string]string{
+		"url":         "https://example.com/docs/documented",
+		"description": "Documented operation",
+	}, api.Paths["/documented"]["get"].ExternalDocs)
+	assert.Nil(t, api.Paths["/undocumented"]["get"].ExternalDocs)
+}
+
+// TestOperationServers tests that the servers of an
+// operation override the servers of the spec.
+func TestOperationServers(t *testing.T) {
+	fizz := New()
+	fizz.Generator().SetServers([]*openapi.Server{
+		{URL: "https://api.example.com"},
+	})
+	handler := tonic.Handler(func(c *gin.Context) error { return nil }, 200)
+
+	fizz.GET("/reports", []OperationOption{
+		ID("ListReports"),
+		Servers(
+			&openapi.Server{URL: "https://reports.example.com", Description: "Reporting backend"},
+			&openapi.Server{URL: "https://reports-eu.example.com"},
+		),
+	}, handler)
+	fizz.GET("/users", []OperationOption{
+		ID("ListUsers"),
+	}, handler)
+
+	b, err := json.Marshal(fizz.Generator().API())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var api struct {
+		Servers []map[string]string `json:"servers"`
+		Paths   map[string]map[string]struct {
+			Servers []map[string]string `json:"servers"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(b, &api); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []map[string]string{
+		{"url": "https://api.example.com"},
+	}, api.Servers)
+	assert.Equal(t, []map[string]string{
+		{"url": "https://reports.example.com", "description": "Reporting backend"},
+		{"url": "https://reports-eu.example.com"},
+	}, api.Paths["/reports"]["get"].Servers)
+	assert.Nil(t, api.Paths["/users"]["get"].Servers)
+}
+
+// TestCallbacks tests that the callbacks of an
+// operation are marshaled with their path items.
+func TestCallbacks(t *testing.T) {
+	fizz := New()
+
+	handler := tonic.Handler(func(c *gin.Context) error { return nil }, 200)
+
+	fizz.POST("/payments", []OperationOption{
+		ID("CreatePayment"),
+		Callback("paymentStatus", "{$request.body#/callbackUrl}", &openapi.PathItem{
+			POST: &openapi.Operation{
+				Summary: "Payment status changed",
+				RequestBody: &openapi.RequestBody{
+					Content: map[string]*openapi.MediaType{
+						"application/json": {
+							Schema: &openapi.SchemaOrRef{Schema: &openapi.Schema{Type: "object"}},
+						},
+					},
+				},
+				Responses: openapi.Responses{
+					"204": {Response: &openapi.Response{Description: "Acknowledged"}},
+				},
+			},
+		}),
+	}, handler)
+
+	b, err := json.Marshal(fizz.Generator().API())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var api openapi.OpenAPI
+	if err := json.Unmarshal(b, &api); err != nil {
+		t.Fatal(err)
+	}
+	callbacks := api.Paths["/payments"].POST.Callbacks
+	if !assert.Contains(t, callbacks, "paymentStatus") {
+		return
+	}
+	cb := *callbacks["paymentStatus"]
+	if !assert.Contains(t, cb, "{$request.body#/callbackUrl}") {
+		return
+	}
+	op := cb["{$request.body#/callbackUrl}"].POST
+	if assert.NotNil(t, op) {
+		assert.Equal(t, "Payment status changed", op.Summary)
+		assert.Equal(t, "object", op.RequestBody.Content["application/json"].Schema.Type)
+		assert.Equal(t, "Acknowledged", op.Responses["204"].Description)
+	}
+}
+
+// TestResponseLinks tests that a response can
+// link to another operation.
+func TestResponseLinks(t *testing.T) {
+	fizz := New()
+
+	type User struct {
+		ID string `json:"id"`
+	}
+	type GetUserInput struct {
+		ID string `path:"id"`
+	}
+	fizz.POST("/users", []OperationOption{
+		ID("CreateUser"),
+		ResponseLink("201", "GetUserByID", &openapi.Link{
+			OperationID: "GetUser",
+			Parameters: map[string]interface{}{
+				"id": "$response.body#/id",
+			},
+			Description: "The id of the created user can be used to get it.",
+		}),
+	}, tonic.Handler(func(c *gin.Context) (*User, error) { return &User{}, nil }, 201))
+
+	fizz.GET("/users/:id", []OperationOption{
+		ID("GetUser"),
+	}, tonic.Handler(func(c *gin.Context, in *GetUserInput) (*User, error) { return &User{}, nil }, 200))
+
+	// The response must exist.
+	assert.Panics(t, func() {
+		fizz.DELETE("/users/:id", []OperationOption{
+			ID("DeleteUser"),
+			ResponseLink("200", "GetUserByID", &openapi.Link{OperationID: "GetUser"}),
+		}, tonic.Handler(func(c *gin.Context, in *GetUserInput) error { return nil }, 204))
+	})
+
+	b, err := json.Marshal(fizz.Generator().API())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var api openapi.OpenAPI
+	if err := json.Unmarshal(b, &api); err != nil {
+		t.Fatal(err)
+	}
+	links := api.Paths["/users"].POST.Responses["201"].Links
+	if assert.Contains(t, links, "GetUserByID") {
+		link := links["GetUserByID"]
+		assert.Equal(t, api.Paths["/users/{id}"].GET.ID, link.OperationID)
+		assert.Equal(t, map[string]interface{}{"id": "$response.body#/id"}, link.Parameters)
+		assert.NotEmpty(t, link.Description)
+	}
+}
+
+// TestGroupSecurity tests that the operations of a group
+// and its subgroups inherit the security requirements of
+// the group, unless they override them.
+func TestGroupSecurity(t *testing.T) {
+	fizz := New()
+
+	handler := tonic.Handler(func(c *gin.Context) error { return nil }, 200)
+
+	grp := fizz.Group("/app/user", "User", "User operations").Security("bearer")
+	grp.GET("/profile", []OperationOption{ID("GetProfile")}, handler)
+	grp.POST("/profile", []OperationOption{ID("UpdateProfile")}, handler)
+	grp.GET("/avatar", []OperationOption{
+		ID("GetAvatar"),
+		WithSecurity("oauth2", "read:avatar"),
+	}, handler)
+	grp.GET("/status", []OperationOption{
+		ID("GetStatus"),
+		WithoutSecurity(),
+	}, handler)
+
+	sub := grp.Group("/settings", "Settings", "User settings")
+	sub.GET("", []OperationOption{ID("GetSettings")}, handler)
+
+	fizz.GET("/health", []OperationOption{ID("Health")}, handler)
+
+	bearer := []*openapi.SecurityRequirement{{"bearer": []string{}}}
+	paths := fizz.Generator().API().Paths
+
+	assert.Equal(t, bearer, paths["/app/user/profile"].GET.Security)
+	assert.Equal(t, bearer, paths["/app/user/profile"].POST.Security)
+	assert.Equal(t, bearer, paths["/app/user/settings"].GET.Security)
+	assert.Equal(t,
+		[]*openapi.SecurityRequirement{{"oauth2": []string{"read:avatar"}}},
+		paths["/app/user/avatar"].GET.Security,
+	)
+	assert.NotNil(t, paths["/app/user/status"].GET.Security)
+	assert.Empty(t, paths["/app/user/status"].GET.Security)
+	assert.Nil(t, paths["/health"].GET.Security)
+}
+
+// TestDeprecated tests that an operation can be marked
+// as deprecated with an option or with its group.
+func TestDeprecated(t *testing.T) {
+	fizz := New()
+
+	handler := tonic.Handler(func(c *gin.Context) error { return nil }, 200)
+
+	fizz.GET("/v2/users", []OperationOption{ID("ListUsers")}, handler)
+	fizz.GET("/v2/legacy", []OperationOption{ID("GetLegacy"), Deprecated(true)}, handler)
+
+	v1 := fizz.Group("/v1", "V1", "Version 1").Deprecated()
+	v1.GET("/users", []OperationOption{ID("ListUsersV1")}, handler)
+	v1.GET("/health", []OperationOption{ID("HealthV1"), Deprecated(false)}, handler)
+
+	sub := v1.Group("/admin", "Admin", "Administration")
+	sub.GET("/users", []OperationOption{ID("ListAdminUsersV1")}, handler)
+
+	paths := fizz.Generator().API().Paths
+
+	assert.False(t, paths["/v2/users"].GET.Deprecated)
+	assert.True(t, paths["/v2/legacy"].GET.Deprecated)
+	assert.True(t, paths["/v1/users"].GET.Deprecated)
+	assert.False(t, paths["/v1/health"].GET.Deprecated)
+	assert.True(t, paths["/v1/admin/users"].GET.Deprecated)
+}
+
+type fileUploadReq struct {
+	File   *multipart.FileHeader `form:"file"`
+	NoSave string                `form:"noSave"`
+	Cover  string                `query:"cover"`
+}
+
+type multiFileUploadReq struct {
+	Files []*multipart.FileHeader `form:"files"`
+	Dir   string                  `form:"dir"`
+}
+
+// TestFileUpload tests that the file fields of a
+// multipart form are described as binary strings,
+// and that the multipart media type is used for the
+// inputs with file fields by default.
+func TestFileUpload(t *testing.T) {
+	fizz := New()
+
+	fizz.POST("/upload", []OperationOption{ID("Upload")},
+		tonic.Handler(func(c *gin.Context, in *fileUploadReq) error {
+			return nil
+		}, 200),
+	)
+	fizz.POST("/uploads", []OperationOption{ID("MultiUpload")},
+		tonic.Handler(func(c *gin.Context, in *multiFileUploadReq) error {
+			return nil
+		}, 200, func(r *tonic.Route) {
+			r.SetRequestMediaType("multipart/form-data")
+		}),
+	)
+	assert.Empty(t, fizz.Errors())
+
+	paths := fizz.Generator().API().Paths
+
+	content := paths["/upload"].POST.RequestBody.Content
+	if assert.Contains(t, content, "multipart/form-data") {
+		props := content["multipart/form-data"].Schema.Properties
+		assert.Equal(t, "string", props["file"].Type)
+		assert.Equal(t, "binary", props["file"].Format)
+		assert.Equal(t, "string", props["noSave"].Type)
+		assert.Empty(t, props["noSave"].Format)
+	}
+	params := paths["/upload"].POST.Parameters
+	if assert.Len(t, params, 1) {
+		assert.Equal(t, "cover", params[0].Name)
+	}
+	content = paths["/uploads"].POST.RequestBody.Content
+	if assert.Contains(t, content, "multipart/form-data") {
+		files := content["multipart/form-data"].Schema.Properties["files"]
+		assert.Equal(t, "array", files.Type)
+		if assert.NotNil(t, files.Items) {
+			assert.Equal(t, "string", files.Items.Type)
+			assert.Equal(t, "binary", files.Items.Format)
+		}
+	}
+}
+
+// TestHidden tests that a hidden operation is served
+// but is absent from the specification.
+func TestHidden(t *testing.T) {
+	type In struct {
+		Name string `query:"name"`
+	}
+	fizz := New()
+
+	fizz.GET("/debug", []OperationOption{ID("Debug"), Hidden()},
+		tonic.Handler(func(c *gin.Context, in *In) (string, error) {
+			return "hello " + in.Name, nil
+		}, 200),
+	)
+	fizz.GET("/users", []OperationOption{ID("ListUsers")},
+		tonic.Handler(func(c *gin.Context) error { return nil }, 200),
+	)
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/debug?name=fizz", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fizz.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `"hello fizz"`, w.Body.String())
+
+	api := fizz.Generator().API()
+	assert.NotContains(t, api.Paths, "/debug")
+	assert.Contains(t, api.Paths, "/users")
+}
+
+// TestRequestExamples tests that the named examples
+// of a request body are added to its media type.
+func TestRequestExamples(t *testing.T) {
+	type In struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	fizz := New()
+
+	handler := tonic.Handler(func(c *gin.Context, in *In) error { return nil }, 200)
+
+	fizz.POST("/users", []OperationOption{
+		ID("CreateUser"),
+		RequestExample("adult", In{Name: "John", Age: 42}),
+		RequestExample("child", In{Name: "Jane", Age: 7}),
+	}, handler)
+
+	api := fizz.Generator().API()
+	mt := api.Paths["/users"].POST.RequestBody.Content["application/json"]
+	if assert.NotNil(t, mt) {
+		assert.Nil(t, mt.Example)
+		assert.Len(t, mt.Examples, 2)
+		assert.Equal(t, In{Name: "John", Age: 42}, mt.Examples["adult"].Value)
+		assert.Equal(t, In{Name: "Jane", Age: 7}, mt.Examples["child"].Value)
+	}
+	// Example and examples are mutually exclusive.
+	assert.Panics(t, func() {
+		fizz.POST("/users/import", []OperationOption{
+			ID("ImportUsers"),
+			func(o *openapi.OperationInfo) {
+				o.RequestExample = In{Name: "John"}
+			},
+			RequestExample("adult", In{Name: "John", Age: 42}),
+		}, handler)
+	})
+	// The operation must have a request body.
+	assert.Panics(t, func() {
+		fizz.GET("/users", []OperationOption{
+			ID("ListUsers"),
+			RequestExample("adult", In{Name: "John", Age: 42}),
+		}, tonic.Handler(func(c *gin.Context) error { return nil }, 200))
+	})
+}
+
+// TestDefaultContentType tests that the default content
+// type of the generator is used by the request and the
+// response bodies of the operations.
+func TestDefaultContentType(t *testing.T) {
+	type In struct {
+		Name string `json:"name"`
+	}
+	type Out struct {
+		ID string `json:"id"`
+	}
+	fizz := New()
+	fizz.Generator().SetDefaultContentType("application/vnd.api+json")
+
+	fizz.POST("/users", []OperationOption{ID("CreateUser")},
+		tonic.Handler(func(c *gin.Context, in *In) (*Out, error) { return nil, nil }, 201))
+
+	op := fizz.Generator().API().Paths["/users"].POST
+
+	assert.Len(t, op.RequestBody.Content, 1)
+	mt := op.RequestBody.Content["application/vnd.api+json"]
+	if assert.NotNil(t, mt) {
+		assert.Equal(t, "#/components/schemas/CreateUserInput", mt.Schema.Ref)
+		assert.Contains(t, fizz.Generator().API().Components.Schemas["CreateUserInput"].Properties, "name")
+	}
+	assert.Len(t, op.Responses["201"].Content, 1)
+	assert.Contains(t, op.Responses["201"].Content, "application/vnd.api+json")
+}
+
+// TestNewFromEngineWithConfig tests that the tags of the
+// config are used by the generator of the Fizz instance.
+func TestNewFromEngineWithConfig(t *testing.T) {
+	type In struct {
+		Page int    `q:"page" binding:"required"`
+		Sort string `query:"sort"`
+	}
+	fizz := NewFromEngineWithConfig(gin.New(), &openapi.SpecGenConfig{
+		ValidatorTag:     "binding",
+		QueryLocationTag: "q",
+	})
+	fizz.GET("/items", []OperationOption{ID("ListItems")},
+		tonic.Handler(func(c *gin.Context, in *In) error { return nil }, 200))
+
+	op := fizz.Generator().API().Paths["/items"].GET
+	if assert.Len(t, op.Parameters, 1) {
+		p := op.Parameters[0]
+		assert.Equal(t, "page", p.Name)
+		assert.Equal(t, "query", p.In)
+		assert.True(t, p.Required)
+	}
+	assert.Empty(t, fizz.Errors())
+}
+
+// TestDefaultHooks tests that the default hooks and media
+// types of Fizz are used by the Tonic-wrapped handlers that
+// don't set their own.
+func TestDefaultHooks(t *testing.T) {
+	type In struct {
+		Name string `form:"name"`
+	}
+	fizz := New()
+
+	var binds, renders int
+	fizz.SetDefaultBindHook(func(c *gin.Context, v interface{}) error {
+		binds++
+		return c.ShouldBind(v)
+	})
+	fizz.SetDefaultRenderHook(func(c *gin.Context, status int, payload interface{}) {
+		renders++
+		c.XML(status, payload)
+	})
+	fizz.SetDefaultRequestMediaType("application/x-www-form-urlencoded")
+	fizz.SetDefaultResponseMediaType("application/xml")
+
+	handler := func(c *gin.Context, in *In) (*In, error) { return in, nil }
+
+	fizz.POST("/a", []OperationOption{ID("A")}, tonic.Handler(handler, 200))
+	fizz.Group("/group", "Group", "").POST("/b", []OperationOption{ID("B")}, tonic.Handler(handler, 200))
+	fizz.POST("/c", []OperationOption{ID("C")}, tonic.Handler(handler, 200, func(r *tonic.Route) {
+		r.SetRenderHook(func(c *gin.Context, status int, payload interface{}) {
+			c.JSON(status, payload)
+		})
+	}))
+	for _, path := range []string{"/a", "/group/b", "/c"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader("name=John"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		fizz.ServeHTTP(w, req)
+
+		assert.Equal(t, 200, w.Code, path)
+		assert.Contains(t, w.Body.String(), "John", path)
+	}
+	assert.Equal(t, 3, binds)
+	assert.Equal(t, 2, renders)
+
+	// The routes of another instance use the hooks of Tonic.
+	other := New()
+	other.POST("/d", []OperationOption{ID("D")}, tonic.Handler(func(c *gin.Context) error { return nil }, 204))
+
+	w := httptest.NewRecorder()
+	other.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/d", nil))
+	assert.Equal(t, 204, w.Code)
+	assert.Equal(t, 2, renders)
+
+	op := fizz.Generator().API().Paths["/a"].POST
+	assert.Contains(t, op.RequestBody.Content, "application/x-www-form-urlencoded")
+	assert.Contains(t, op.Responses["200"].Content, "application/xml")
+}
+
+// TestResponseExamples tests that the named examples
+// of a response are added to its media type.
+func TestResponseExamples(t *testing.T) {
+	type Out struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	fizz := New()
+
+	handler := tonic.Handler(func(c *gin.Context) (*Out, error) { return nil, nil }, 200)
+
+	fizz.GET("/users/me", []OperationOption{
+		ID("GetMe"),
+		ResponseExample("200", "adult", Out{Name: "John", Age: 42}),
+		ResponseExample("200", "child", Out{Name: "Jane", Age: 7}),
+	}, handler)
+
+	api := fizz.Generator().API()
+	mt := api.Paths["/users/me"].GET.Responses["200"].Content["application/json"]
+	if assert.NotNil(t, mt) {
+		assert.Nil(t, mt.Example)
+		assert.Len(t, mt.Examples, 2)
+		assert.Equal(t, Out{Name: "John", Age: 42}, mt.Examples["adult"].Value)
+		assert.Equal(t, Out{Name: "Jane", Age: 7}, mt.Examples["child"].Value)
+	}
+	// Example and examples are mutually exclusive.
+	assert.Panics(t, func() {
+		fizz.GET("/users/:id", []OperationOption{
+			ID("GetUser"),
+			Response("404", "not found", Out{}, nil, Out{Name: "John"}),
+			ResponseExample("404", "adult", Out{Name: "John", Age: 42}),
+		}, handler)
+	})
+	// The response must exist.
+	assert.Panics(t, func() {
+		fizz.GET("/users/self", []OperationOption{
+			ID("GetSelf"),
+			ResponseExample("404", "adult", Out{Name: "John", Age: 42}),
+		}, handler)
+	})
+}
+
+// TestStatusContent tests that the default response
+// of an operation can have additional media types.
+func TestStatusContent(t *testing.T) {
+	type Out struct {
+		Name string `json:"name"`
+	}
+	fizz := New()
+
+	handler := tonic.Handler(func(c *gin.Context) (*Out, error) { return nil, nil }, 200)
+
+	fizz.GET("/users", []OperationOption{
+		ID("GetUsers"),
+		StatusContent("text/csv", ""),
+	}, handler)
+
+	content := fizz.Generator().API().Paths["/users"].GET.Responses["200"].Content
+	assert.Len(t, content, 2)
+	assert.Contains(t, content, "application/json")
+	if assert.Contains(t, content, "text/csv") {
+		assert.Equal(t, "string", content["text/csv"].Schema.Type)
+	}
+}
+
+// TestResponseExampleRef tests that the operations
+// can reference the examples of the components.
+func TestResponseExampleRef(t *testing.T) {
+	type Out struct {
+		Message string `json:"message"`
+	}
+	fizz := New()
+
+	err := fizz.Generator().AddComponentExample("NotFound", &openapi.Example{
+		Summary: "Resource not found",
+		Value:   Out{Message: "not found"},
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, fizz.Generator().AddComponentExample("", &openapi.Example{}))
+	assert.NotNil(t, fizz.Generator().AddComponentExample("Nil", nil))
+
+	handler := tonic.Handler(func(c *gin.Context) (*Out, error) { return nil, nil }, 200)
+
+	for i, path := range []string{"/users/:id", "/groups/:id"} {
+		fizz.GET(path, []OperationOption{
+			ID(fmt.Sprintf("Get%d", i)),
+			Response("404", "not found", Out{}, nil, nil),
+			ResponseExampleRef("404", "notFound", "NotFound"),
+		}, handler)
+	}
+	b, err := json.Marshal(fizz.Generator().API())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec struct {
+		Components struct {
+			Examples map[string]json.RawMessage `json:"examples"`
+		} `json:"components"`
+		Paths map[string]map[string]struct {
+			Responses map[string]struct {
+				Content map[string]struct {
+					Examples map[string]json.RawMessage `json:"examples"`
+				} `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(b, &spec); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Contains(t, spec.Components.Examples, "NotFound") {
+		assert.JSONEq(t, `{
+			"summary": "Resource not found",
+			"value": {"message": "not found"}
+		}`, string(spec.Components.Examples["NotFound"]))
+	}
+	for _, path := range []string{"/users/{id}", "/groups/{id}"} {
+		examples := spec.Paths[path]["get"].Responses["404"].Content["application/json"].Examples
+		if assert.Contains(t, examples, "notFound", path) {
+			assert.JSONEq(t, `{"$ref": "#/components/examples/NotFound"}`, string(examples["notFound"]))
+		}
+	}
+}
+
+// TestInputSchema tests that a hand-written schema
+// is used as the schema of the request body.
+func TestInputSchema(t *testing.T) {
+	type In struct {
+		ID   string `path:"id"`
+		Body string `json:"body"`
+	}
+	fizz := New()
+
+	handler := tonic.Handler(func(c *gin.Context, in *In) error { return nil }, 200)
+
+	schema := &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.SchemaOrRef{
+			"name": {Schema: &openapi.Schema{Type: "string"}},
+		},
+		AdditionalProperties: &openapi.SchemaOrRef{Schema: &openapi.Schema{}},
+	}
+	fizz.PUT("/documents/:id", []OperationOption{
+		ID("PutDocument"),
+		InputSchema(schema),
+	}, handler)
+
+	op := fizz.Generator().API().Paths["/documents/{id}"].PUT
+	assert.Len(t, op.Parameters, 1)
+
+	if assert.NotNil(t, op.RequestBody) {
+		mt := op.RequestBody.Content[tonic.MediaType()]
+		if assert.NotNil(t, mt) {
+			b, err := json.Marshal(mt.Schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.JSONEq(t, `{
+				"type": "object",
+				"properties": {
+					"name": {"type": "string"}
+				},
+				"additionalProperties": {}
+			}`, string(b))
+		}
+	}
+	// The fields of the input type are ignored.
+	assert.NotContains(t, fizz.Generator().API().Components.Schemas, "PutDocumentInput")
+
+	// Input model and input schema are mutually exclusive.
+	assert.Panics(t, func() {
+		fizz.POST("/documents", []OperationOption{
+			ID("CreateDocument"),
+			InputModel(In{}),
+			InputSchema(schema),
+		}, handler)
+	})
+	// An operation with the method GET has no body.
+	assert.Panics(t, func() {
+		fizz.GET("/documents/:id", []OperationOption{
+			ID("GetDocument"),
+			InputSchema(schema),
+		}, handler)
+	})
+}
+
+// TestGroupTags tests that the operations of a subgroup
+// are tagged with the names of the parent groups.
+func TestGroupTags(t *testing.T) {
+	fizz := New()
+
+	handler := tonic.Handler(func(c *gin.Context) error { return nil }, 200)
+
+	grp := fizz.Group("/app/user", "UserApiGroup", "User operations")
+	grp.GET("/profile", []OperationOption{ID("GetProfile")}, handler)
+
+	sub := grp.Group("/settings", "Settings", "User settings")
+	sub.GET("", []OperationOption{ID("GetSettings")}, handler)
+
+	noname := sub.Group("/advanced", "", "")
+	noname.GET("", []OperationOption{ID("GetAdvancedSettings")}, handler)
+
+	fizz.GET("/health", []OperationOption{ID("Health")}, handler)
+
+	api := fizz.Generator().API()
+
+	assert.Equal(t, []string{"UserApiGroup"}, api.Paths["/app/user/profile"].GET.Tags)
+	assert.Equal(t, []string{"UserApiGroup", "Settings"}, api.Paths["/app/user/settings"].GET.Tags)
+	assert.Equal(t, []string{"UserApiGroup", "Settings"}, api.Paths["/app/user/settings/advanced"].GET.Tags)
+	assert.Empty(t, api.Paths["/health"].GET.Tags)
+
+	descs := make(map[string]string)
+	for _, tag := range api.Tags {
+		descs[tag.Name] = tag.Description
+	}
+	assert.Equal(t, "User operations", descs["UserApiGroup"])
+	assert.Equal(t, "User settings", descs["Settings"])
+}
+
+// TestOperationTags tests that an operation can have
+// additional tags, which are added to the specification.
+func TestOperationTags(t *testing.T) {
+	fizz := New()
+
+	handler := tonic.Handler(func(c *gin.Context) error { return nil }, 200)
+
+	fizz.Generator().AddTag("Webhooks", "Webhook operations")
+
+	grp := fizz.Group("/users", "Users", "User operations")
+	grp.GET("", []OperationOption{
+		ID("ListUsers"),
+		Tags("Beta", "Webhooks"),
+	}, handler)
+
+	api := fizz.Generator().API()
+
+	assert.Equal(t, []string{"Beta", "Webhooks", "Users"}, api.Paths["/users"].GET.Tags)
+
+	descs := make(map[string]string)
+	for _, tag := range api.Tags {
+		descs[tag.Name] = tag.Description
+	}
+	assert.Len(t, descs, 3)
+	assert.Equal(t, "User operations", descs["Users"])
+	assert.Equal(t, "Webhook operations", descs["Webhooks"])
+	assert.Contains(t, descs, "Beta")
+	assert.Empty(t, descs["Beta"])
+}
+
+// TestSpecHandlerFormats tests that the OpenAPI handler
+// serves the same spec in JSON and YAML, with the proper
+// content type and an ordered YAML document.
+func TestSpecHandlerFormats(t *testing.T) {
+	fizz := New()
+
+	fizz.GET("/test/:a", []OperationOption{ID("GetTest")},
+		tonic.Handler(func(c *gin.Context, in *testInputModel1) (*T, error) {
+			return &T{}, nil
+		}, 200),
+	)
+	fizz.Generator().SetServers([]*openapi.Server{{URL: "https://foo.bar"}})
+
+	infos := &openapi.Info{
+		Title:   "Test Server",
+		Version: "1.0.0",
+	}
+	fizz.GET("/openapi.json", nil, fizz.OpenAPI(infos, "json"))
+	fizz.GET("/openapi.yaml", nil, fizz.OpenAPI(infos, "yaml"))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fizz.ServeHTTP(w, req)
+		assert.Equal(t, 200, w.Code)
+		return w
+	}
+	respJSON := get("/openapi.json")
+	assert.Equal(t, "application/json; charset=utf-8", respJSON.Header().Get("Content-Type"))
+
+	respYAML := get("/openapi.yaml")
+	assert.Equal(t, "application/x-yaml; charset=utf-8", respYAML.Header().Get("Content-Type"))
+
+	// The top-level keys of the YAML document must
+	// follow the order of the fields of the spec.
+	specYAML := respYAML.Body.String()
+	last := -1
+	for _, key := range []string{"openapi:", "info:", "servers:", "paths:", "components:"} {
+		idx := strings.Index(specYAML, "\n"+key)
+		if key == "openapi:" {
+			idx = strings.Index(specYAML, key)
+		}
+		assert.Greater(t, idx, last, key)
+		last = idx
+	}
+	// The YAML document must describe the same spec.
+	var y interface{}
+	if err := yaml.Unmarshal(respYAML.Body.Bytes(), &y); err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(yamlToJSON(y))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := diffJSON(b, respJSON.Body.Bytes())
+	if err != nil {
+		t.Error(err)
+	}
+	assert.True(t, m)
+}
+
+// yamlToJSON converts the maps with interface keys
+// unmarshaled from a YAML document to maps with
+// string keys that can be marshaled to JSON.
+func yamlToJSON(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(vv))
+		for k, e := range vv {
+			m[fmt.Sprint(k)] = yamlToJSON(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range vv {
+			vv[i] = yamlToJSON(e)
+		}
+	}
+	return v
+}
+
 // TestInvalidContentTypeOpenAPIHandler tests that the
 // OpenAPI handler will panic if the given content type
 // is invalid.
@@ -455,6 +1377,143 @@ func TestInvalidContentTypeOpenAPIHandler(t *testing.T) {
 	})
 }
 
+// TestCachedOpenAPIHandler tests that the cached spec
+// is served with an ETag, that a request with a matching
+// If-None-Match header gets a 304 response, and that the
+// cache is invalidated when an operation is registered.
+func TestCachedOpenAPIHandler(t *testing.T) {
+	fizz := New()
+
+	fizz.GET("/a", []OperationOption{ID("GetA")}, tonic.Handler(func(c *gin.Context) error { return nil }, 200))
+	fizz.GET("/openapi.json", nil, fizz.OpenAPI(nil, "json", WithCache(true)))
+	fizz.GET("/openapi.yaml", nil, fizz.OpenAPI(nil, "yaml", WithCache(true)))
+
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		fizz.ServeHTTP(w, req)
+		return w
+	}
+	resp := get("/openapi.json", "")
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header().Get("Content-Type"))
+	etag := resp.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	var api openapi.OpenAPI
+	err := json.Unmarshal(resp.Body.Bytes(), &api)
+	assert.Nil(t, err)
+	assert.Contains(t, api.Paths, "/a")
+
+	resp = get("/openapi.json", etag)
+	assert.Equal(t, 304, resp.Code)
+	assert.Empty(t, resp.Body.Bytes())
+
+	resp = get("/openapi.yaml", "")
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, "application/x-yaml; charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.NotEqual(t, etag, resp.Header().Get("ETag"))
+
+	// Registering an operation invalidates the cache.
+	fizz.GET("/b", []OperationOption{ID("GetB")}, tonic.Handler(func(c *gin.Context) error { return nil }, 200))
+
+	resp = get("/openapi.json", etag)
+	assert.Equal(t, 200, resp.Code)
+	assert.NotEqual(t, etag, resp.Header().Get("ETag"))
+	assert.Contains(t, resp.Body.String(), `"/b"`)
+}
+
+// TestGzipOpenAPIHandler tests that the spec is compressed
+// with gzip for the clients that accept it, with and
+// without cache, and served plain to the others.
+func TestGzipOpenAPIHandler(t *testing.T) {
+	fizz := New()
+
+	fizz.GET("/a", []OperationOption{ID("GetA")}, tonic.Handler(func(c *gin.Context) error { return nil }, 200))
+	fizz.GET("/openapi.json", nil, fizz.OpenAPI(nil, "json"))
+	fizz.GET("/cached.json", nil, fizz.OpenAPI(nil, "json", WithCache(true)))
+
+	get := func(path, encoding, etag string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if encoding != "" {
+			req.Header.Set("Accept-Encoding", encoding)
+		}
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		fizz.ServeHTTP(w, req)
+		return w
+	}
+	for _, path := range []string{"/openapi.json", "/cached.json"} {
+		plain := get(path, "", "")
+		assert.Equal(t, 200, plain.Code)
+		assert.Empty(t, plain.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", plain.Header().Get("Vary"))
+
+		// A gzip coding with a zero quality is refused.
+		resp := get(path, "gzip;q=0, identity", "")
+		assert.Empty(t, resp.Header().Get("Content-Encoding"))
+
+		resp = get(path, "deflate, gzip;q=0.8", "")
+		assert.Equal(t, 200, resp.Code)
+		assert.Equal(t, "gzip", resp.Header().Get("Content-Encoding"))
+		assert.Equal(t, "application/json; charset=utf-8", resp.Header().Get("Content-Type"))
+
+		r, err := gzip.NewReader(resp.Body)
+		if !assert.Nil(t, err) {
+			continue
+		}
+		b, err := ioutil.ReadAll(r)
+		assert.Nil(t, err)
+		assert.JSONEq(t, plain.Body.String(), string(b), path)
+	}
+	// The compressed and plain documents have different
+	// ETags, that are matched independently.
+	plain := get("/cached.json", "", "")
+	gz := get("/cached.json", "gzip", "")
+	assert.NotEqual(t, plain.Header().Get("ETag"), gz.Header().Get("ETag"))
+	assert.Equal(t, 304, get("/cached.json", "gzip", gz.Header().Get("ETag")).Code)
+	assert.Equal(t, 200, get("/cached.json", "", gz.Header().Get("ETag")).Code)
+}
+
+func benchmarkOpenAPIHandler(b *testing.B, opts ...OpenAPIOption) {
+	fizz := New()
+
+	for i := 0; i < 300; i++ {
+		fizz.GET(fmt.Sprintf("/test/%d/:a", i), []OperationOption{ID(fmt.Sprintf("GetTest%d", i))},
+			tonic.Handler(func(c *gin.Context, in *testInputModel1) (*T, error) {
+				return &T{}, nil
+			}, 200),
+		)
+	}
+	fizz.GET("/openapi.json", nil, fizz.OpenAPI(nil, "json", opts...))
+
+	req, err := http.NewRequest("GET", "/openapi.json", nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		fizz.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+func BenchmarkOpenAPIHandler(b *testing.B) {
+	benchmarkOpenAPIHandler(b)
+}
+
+func BenchmarkCachedOpenAPIHandler(b *testing.B) {
+	benchmarkOpenAPIHandler(b, WithCache(true))
+}
+
 // TestMultipleTonicHandler tests that adding more than
 // one tonic-wrapped handler to a Fizz operation panics.
 func TestMultipleTonicHandler(t *testing.T) {
@@ -581,3 +1640,182 @@ func diffYAML(a, b []byte) (bool, error) {
 	}
 	return reflect.DeepEqual(j2, j1), nil
 }
+
+// TestExtension tests that the specification
+// extensions are added to the operations.
+func TestExtension(t *testing.T) {
+	fizz := New()
+
+	fizz.GET("/users", []OperationOption{
+		ID("ListUsers"),
+		Extension("x-internal", true),
+		Extension("x-ratelimit", map[string]int{"rpm": 60}),
+	}, tonic.Handler(func(c *gin.Context) error { return nil }, 200))
+
+	b, err := json.Marshal(fizz.Generator().API().Paths["/users"].GET)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(b), `"x-internal":true,"x-ratelimit":{"rpm":60}}`)
+
+	assert.Panics(t, func() {
+		fizz.GET("/orders", []OperationOption{ID("ListOrders"), Extension("ratelimit", 60)},
+			tonic.Handler(func(c *gin.Context) error { return nil }, 200),
+		)
+	})
+}
+
+// TestValidateRequestMiddleware tests that the middleware
+// rejects the requests that don't match the schemas of
+// their operation.
+func TestValidateRequestMiddleware(t *testing.T) {
+	type in struct {
+		ID       int    `path:"id" validate:"min=1"`
+		Quantity int    `json:"quantity" validate:"max=10"`
+		Name     string `json:"name" validate:"required"`
+	}
+	fizz := New()
+	fizz.Use(fizz.ValidateRequestMiddleware())
+
+	fizz.POST("/items/:id", []OperationOption{ID("UpdateItem")},
+		tonic.Handler(func(c *gin.Context, i *in) (*in, error) {
+			return i, nil
+		}, 200),
+	)
+	fizz.GET("/health", nil, func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	for _, tt := range []struct {
+		path    string
+		body    string
+		status  int
+		details []string
+	}{
+		{"/items/1", `{"quantity":11,"name":"foo"}`, http.StatusBadRequest, []string{"body.quantity: must be lower than or equal to 10"}},
+		{"/items/0", `{"quantity":"5"}`, http.StatusBadRequest, []string{
+			"path parameter id: must be greater than or equal to 1",
+			"body.name: is required",
+			"body.quantity: must be a number",
+		}},
+		{"/items/1", `{"quantity":10,"name":"foo"}`, http.StatusOK, nil},
+	} {
+		req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		fizz.ServeHTTP(w, req)
+
+		if !assert.Equal(t, tt.status, w.Code, tt.body) {
+			continue
+		}
+		if tt.details != nil {
+			var resp struct {
+				Details []string `json:"details"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.details, resp.Details)
+		} else {
+			// The handler can bind the body
+			// read by the middleware.
+			assert.JSONEq(t, `{"ID":1,"quantity":10,"name":"foo"}`, w.Body.String())
+		}
+	}
+	w := httptest.NewRecorder()
+	fizz.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+// TestValidateRequestMiddlewareBody tests that the middleware
+// validates the bodies of any JSON media type, and rejects the
+// requests without the body of an operation that requires it.
+func TestValidateRequestMiddlewareBody(t *testing.T) {
+	type in struct {
+		Quantity int `json:"quantity" validate:"max=10"`
+	}
+	fizz := New()
+	fizz.Use(fizz.ValidateRequestMiddleware())
+
+	handler := tonic.Handler(func(c *gin.Context, i *in) (*in, error) {
+		return i, nil
+	}, 200)
+	fizz.POST("/resources", []OperationOption{ID("CreateResource"), InputMediaType("application/vnd.api+json")}, handler)
+	fizz.POST("/items", []OperationOption{ID("CreateItem"), RequestBodyRequired(true)}, handler)
+
+	for _, tt := range []struct {
+		path, ct, body string
+		status         int
+		details        []string
+	}{
+		{"/resources", "application/vnd.api+json", `{"quantity":11}`, http.StatusBadRequest, []string{"body.quantity: must be lower than or equal to 10"}},
+		{"/resources", "application/vnd.api+json; charset=utf-8", `{"quantity":"5"}`, http.StatusBadRequest, []string{"body.quantity: must be a number"}},
+		{"/resources", "", `{"quantity":11}`, http.StatusBadRequest, []string{"body.quantity: must be lower than or equal to 10"}},
+		{"/items", "application/json", "", http.StatusBadRequest, []string{"body: is required"}},
+		{"/items", "", "", http.StatusBadRequest, []string{"body: is required"}},
+		{"/items", "application/json", `{"quantity":1}`, http.StatusOK, nil},
+	} {
+		req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+		if tt.ct != "" {
+			req.Header.Set("Content-Type", tt.ct)
+		}
+		w := httptest.NewRecorder()
+		fizz.ServeHTTP(w, req)
+
+		if !assert.Equal(t, tt.status, w.Code, tt.path+" "+tt.body) || tt.details == nil {
+			continue
+		}
+		var resp struct {
+			Details []string `json:"details"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, tt.details, resp.Details)
+	}
+}
+
+// TestDynamicServer tests that the URL of the first server
+// of the spec is computed from the request when the
+// WithDynamicServer option is enabled.
+func TestDynamicServer(t *testing.T) {
+	fizz := New()
+	fizz.Generator().SetServers([]*openapi.Server{
+		{URL: "https://api.example.com/v1", Description: "Production"},
+		{URL: "https://staging.example.com/v1"},
+	})
+	fizz.GET("/static.json", nil, fizz.OpenAPI(nil, "json"))
+	fizz.GET("/openapi.json", nil, fizz.OpenAPI(nil, "json", WithDynamicServer(true)))
+
+	for _, tt := range []struct {
+		url, forwarded string
+		want           []*openapi.Server
+	}{
+		{"https://foo.example/openapi.json", "", []*openapi.Server{
+			{URL: "https://foo.example/v1", Description: "Production"},
+			{URL: "https://staging.example.com/v1"},
+		}},
+		{"http://internal:8080/openapi.json", "https", []*openapi.Server{
+			{URL: "https://internal:8080/v1", Description: "Production"},
+			{URL: "https://staging.example.com/v1"},
+		}},
+		{"https://foo.example/static.json", "", []*openapi.Server{
+			{URL: "https://api.example.com/v1", Description: "Production"},
+			{URL: "https://staging.example.com/v1"},
+		}},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+		if tt.forwarded != "" {
+			req.Header.Set("X-Forwarded-Proto", tt.forwarded)
+		}
+		w := httptest.NewRecorder()
+		fizz.ServeHTTP(w, req)
+
+		var api openapi.OpenAPI
+		if err := json.Unmarshal(w.Body.Bytes(), &api); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, tt.want, api.Servers, tt.url)
+	}
+	// The servers of the generator are unchanged.
+	assert.Equal(t, "https://api.example.com/v1", fizz.Generator().API().Servers[0].URL)
+}
@@ -0,0 +1,158 @@
+package openapi
+
+import "reflect"
+
+// Clone returns a deep copy of the generator. The
+// specification, the registered types and schemas,
+// and the configuration of the clone are independent
+// from the ones of the original generator, which allows
+// to derive several variants of a base specification.
+func (g *Generator) Clone() *Generator {
+	seen := make(map[copyKey]reflect.Value)
+
+	conf := *g.config
+	c := &Generator{
+		config:         &conf,
+		api:            deepCopy(reflect.ValueOf(g.api), seen).Interface().(*OpenAPI),
+		schemaTypes:    make(map[reflect.Type]struct{}, len(g.schemaTypes)),
+		typeNames:      make(map[reflect.Type]string, len(g.typeNames)),
+		overrides:      make(map[reflect.Type]*Schema, len(g.overrides)),
+		fieldOverrides: make(map[reflect.Type]map[string]*Schema, len(g.fieldOverrides)),
+		interfaces:     make(map[reflect.Type]*interfaceImpls, len(g.interfaces)),
+		enums:          make(map[reflect.Type][]EnumValue, len(g.enums)),
+		schemaExts:     make(map[reflect.Type]map[string]interface{}, len(g.schemaExts)),
+		operationsIDS:  make(map[string]struct{}, len(g.operationsIDS)),
+		defaultResps:   append([]*OperationResponse(nil), g.defaultResps...),
+		dedupedSchemas: make(map[string]string, len(g.dedupedSchemas)),
+		errors:         append([]error(nil), g.errors...),
+		warnings:       append([]error(nil), g.warnings...),
+		fullNames:      g.fullNames,
+		sortParams:     g.sortParams,
+		sortTags:       g.sortTags,
+		dedupe:         g.dedupe,
+		requireDescs:   g.requireDescs,
+		autoOpIDs:      g.autoOpIDs,
+		durationFormat: g.durationFormat,
+		schemaPrefix:   g.schemaPrefix,
+		schemaSuffix:   g.schemaSuffix,
+		tagOrder:       append([]string(nil), g.tagOrder...),
+		contentType:    g.contentType,
+		arrayStyle:     g.arrayStyle,
+		arrayExplode:   g.arrayExplode,
+		maxDepth:       g.maxDepth,
+	}
+	for t := range g.schemaTypes {
+		c.schemaTypes[t] = struct{}{}
+	}
+	for t, name := range g.typeNames {
+		c.typeNames[t] = name
+	}
+	for t, s := range g.overrides {
+		c.overrides[t] = deepCopy(reflect.ValueOf(s), seen).Interface().(*Schema)
+	}
+	for t, fields := range g.fieldOverrides {
+		c.fieldOverrides[t] = deepCopy(reflect.ValueOf(fields), seen).Interface().(map[string]*Schema)
+	}
+	for t, impls := range g.interfaces {
+		c.interfaces[t] = &interfaceImpls{
+			types:         append([]reflect.Type(nil), impls.types...),
+			discriminator: impls.discriminator,
+		}
+		if impls.mapping != nil {
+			c.interfaces[t].mapping = make(map[string]reflect.Type, len(impls.mapping))
+			for value, impl := range impls.mapping {
+				c.interfaces[t].mapping[value] = impl
+			}
+		}
+	}
+	for t, values := range g.enums {
+		c.enums[t] = append([]EnumValue(nil), values...)
+	}
+	for t, ext := range g.schemaExts {
+		c.schemaExts[t] = deepCopy(reflect.ValueOf(ext), seen).Interface().(map[string]interface{})
+	}
+	for id := range g.operationsIDS {
+		c.operationsIDS[id] = struct{}{}
+	}
+	for fp, name := range g.dedupedSchemas {
+		c.dedupedSchemas[fp] = name
+	}
+	return c
+}
+
+// copyKey identifies a pointer or a map
+// that has already been copied.
+type copyKey struct {
+	t reflect.Type
+	p uintptr
+}
+
+// deepCopy returns a deep copy of the value v. The pointers
+// and maps that are referenced more than once are copied
+// once, to preserve the sharing of the values in the copy.
+// The unexported fields of the structs are copied as is.
+func deepCopy(v reflect.Value, seen map[copyKey]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		k := copyKey{t: v.Type(), p: v.Pointer()}
+		if c, ok := seen[k]; ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		seen[k] = c
+		c.Elem().Set(deepCopy(v.Elem(), seen))
+
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		k := copyKey{t: v.Type(), p: v.Pointer()}
+		if c, ok := seen[k]; ok {
+			return c
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		seen[k] = c
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value(), seen))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), seen))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i), seen))
+		}
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem(), seen))
+
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				c.Field(i).Set(deepCopy(v.Field(i), seen))
+			}
+		}
+		return c
+	}
+	return v
+}
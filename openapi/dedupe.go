@@ -0,0 +1,89 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+)
+
+// dedupeSchemas hoists the inlined object schemas that are
+// structurally identical into the components of the spec,
+// and replaces each of their occurrences with a reference
+// to the shared component.
+func (g *Generator) dedupeSchemas() {
+	// Outermost schemas are hoisted first, repeat
+	// until the nested schemas are stable.
+	for g.hoistDuplicateSchemas() {
+	}
+}
+
+// hoistDuplicateSchemas runs a single pass of deduplication
+// and reports whether an inlined schema was replaced.
+func (g *Generator) hoistDuplicateSchemas() bool {
+	counts := make(map[string]int)
+
+	walkSchemaRefs(g.api, func(sor *SchemaOrRef, root bool) bool {
+		if fp, ok := schemaFingerprint(sor); ok && !root {
+			counts[fp]++
+		}
+		return true
+	})
+	var replaced bool
+
+	walkSchemaRefs(g.api, func(sor *SchemaOrRef, root bool) bool {
+		fp, ok := schemaFingerprint(sor)
+		if !ok || root {
+			return true
+		}
+		name, ok := g.dedupedSchemas[fp]
+		if !ok {
+			if counts[fp] < 2 {
+				return true
+			}
+			name = g.dedupedSchemaName(fp)
+			g.dedupedSchemas[fp] = name
+			g.api.Components.Schemas[name] = &SchemaOrRef{Schema: sor.Schema}
+		}
+		sor.Schema = nil
+		sor.Reference = &Reference{Ref: componentsSchemaPath + name}
+		replaced = true
+
+		// The nested schemas now belong to the
+		// component, skip them.
+		return false
+	})
+	return replaced
+}
+
+// dedupedSchemaName returns a stable name for the component
+// of a deduplicated schema, derived from its fingerprint.
+// The name contains a dot, which is removed from the names
+// of the Go types, to avoid conflicts with their components.
+func (g *Generator) dedupedSchemaName(fp string) string {
+	h := fnv.New32a()
+	h.Write([]byte(fp))
+
+	name := g.componentName(fmt.Sprintf("Inline.%08x", h.Sum32()))
+	for i := 2; ; i++ {
+		if _, ok := g.api.Components.Schemas[name]; !ok {
+			return name
+		}
+		name = g.componentName(fmt.Sprintf("Inline.%08x.%d", h.Sum32(), i))
+	}
+}
+
+// schemaFingerprint returns the JSON representation of an
+// inlined object schema, which is identical for schemas of
+// the same structure. It returns false if sor is not an
+// inlined schema with properties.
+func schemaFingerprint(sor *SchemaOrRef) (string, bool) {
+	s := sor.Schema
+	if s == nil || s.Type != "object" || len(s.Properties) == 0 {
+		return "", false
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
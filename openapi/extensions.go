@@ -0,0 +1,89 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// validateExtension returns an error if the key
+// is not the name of a specification extension.
+func validateExtension(key string) error {
+	if !strings.HasPrefix(key, "x-") || len(key) == len("x-") {
+		return fmt.Errorf("invalid extension %q, the name must start with x-", key)
+	}
+	return nil
+}
+
+// extensionKeys returns the sorted
+// keys of the extensions.
+func extensionKeys(ext map[string]interface{}) []string {
+	keys := make([]string, 0, len(ext))
+	for k := range ext {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// marshalJSONWithExtensions marshals v, which must
+// be marshaled to a JSON object, and appends the
+// extensions to its fields.
+func marshalJSONWithExtensions(v interface{}, ext map[string]interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(ext) == 0 {
+		return b, err
+	}
+	var buf bytes.Buffer
+	buf.Write(b[:len(b)-1])
+
+	for i, k := range extensionKeys(ext) {
+		if i > 0 || len(b) > len("{}") {
+			buf.WriteByte(',')
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := json.Marshal(ext[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// marshalYAMLWithExtensions returns the value to
+// marshal in YAML for v, with the extensions
+// appended to its fields.
+func marshalYAMLWithExtensions(v interface{}, ext map[string]interface{}) (interface{}, error) {
+	if len(ext) == 0 {
+		return v, nil
+	}
+	var ms yaml.MapSlice
+	if s, ok := v.(yaml.MapSlice); ok {
+		ms = s
+	} else {
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.Unmarshal(b, &ms); err != nil {
+			return nil, err
+		}
+	}
+	for _, k := range extensionKeys(ext) {
+		ms = append(ms, yaml.MapItem{Key: k, Value: ext[k]})
+	}
+	return ms, nil
+}
@@ -1,16 +1,16 @@
 package openapi
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
-	"os"
 	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/ccfish86/gadgeto/tonic"
 	"github.com/gofrs/uuid"
@@ -20,8 +20,16 @@ const (
 	version              = "3.0.1"
 	anyMediaType         = "*/*"
 	formatTag            = "format"
+	int64StringFormat    = "int64-string"
 	deprecatedTag        = "deprecated"
 	descriptionTag       = "description"
+	patternTag           = "pattern"
+	multipleOfTag        = "multipleOf"
+	readOnlyTag          = "readonly"
+	writeOnlyTag         = "writeonly"
+	styleTag             = "style"
+	bindingTag           = "binding"
+	enumDescriptionsTag  = "enumDescriptions"
 	componentsSchemaPath = "#/components/schemas/"
 )
 
@@ -31,6 +39,54 @@ var (
 	refRe          = regexp.MustCompile(`[\[\]\.\*,]|(\w+(-\w+)?/)`) // Replace all words that do not conform [RFC3986-compliant]
 )
 
+// parameterStyles maps the parameter locations
+// to the styles that describe their serialization.
+// https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.3.md#style-values
+var parameterStyles = map[string][]string{
+	"path":   {"simple", "label", "matrix"},
+	"query":  {"form", "spaceDelimited", "pipeDelimited", "deepObject"},
+	"header": {"simple"},
+	"cookie": {"form"},
+}
+
+// validatorPatterns maps the validator tags that
+// describe a set of characters to their equivalent
+// regular expressions.
+var validatorPatterns = map[string]string{
+	"alpha":       `^[a-zA-Z]+$`,
+	"alphanum":    `^[a-zA-Z0-9]+$`,
+	"numeric":     `^[-+]?[0-9]+(?:\.[0-9]+)?$`,
+	"hexadecimal": `^(0[xX])?[0-9a-fA-F]+$`,
+}
+
+// validatorFormats maps the validator tags that
+// describe the format of a string to the format
+// of its schema.
+var validatorFormats = map[string]string{
+	"email":            "email",
+	"url":              "uri",
+	"uri":              "uri",
+	"hostname":         "hostname",
+	"hostname_rfc1123": "hostname",
+	"fqdn":             "hostname",
+	"ip":               "ip",
+	"ipv4":             "ipv4",
+	"ipv6":             "ipv6",
+	"uuid":             "uuid",
+	"uuid3":            "uuid",
+	"uuid4":            "uuid",
+	"uuid5":            "uuid",
+	"base64":           "byte",
+	"password":         TypePassword.Format(),
+}
+
+// datetimeFormats maps the layouts of the datetime
+// validator tag to the format of the schema.
+var datetimeFormats = map[string]string{
+	"2006-01-02":                "date",
+	"2006-01-02T15:04:05Z07:00": "date-time",
+}
+
 // mediaTags maps media types to well-known
 // struct tags used for marshaling.
 var mediaTags = map[string]string{
@@ -38,18 +94,54 @@ var mediaTags = map[string]string{
 	"application/xml":  "xml",
 }
 
+// mediaTagName returns the name of the struct tag used
+// to marshal the values of the media type mt, which can
+// use a structured syntax suffix, such as +json.
+func mediaTagName(mt string) string {
+	if tag, ok := mediaTags[mt]; ok {
+		return tag
+	}
+	for _, suffix := range []string{"json", "xml"} {
+		if strings.HasSuffix(mt, "+"+suffix) {
+			return suffix
+		}
+	}
+	return ""
+}
+
 // Generator is an OpenAPI 3 generator.
 type Generator struct {
-	api           *OpenAPI
-	config        *SpecGenConfig
-	schemaTypes   map[reflect.Type]struct{}
-	typeNames     map[reflect.Type]string
-	dataTypes     map[reflect.Type]*OverridedDataType
-	operationsIDS map[string]struct{}
-	errors        []error
-	fullNames     bool
-	sortParams    bool
-	sortTags      bool
+	api            *OpenAPI
+	config         *SpecGenConfig
+	schemaTypes    map[reflect.Type]struct{}
+	typeNames      map[reflect.Type]string
+	overrides      map[reflect.Type]*Schema
+	fieldOverrides map[reflect.Type]map[string]*Schema
+	interfaces     map[reflect.Type]*interfaceImpls
+	enums          map[reflect.Type][]EnumValue
+	schemaExts     map[reflect.Type]map[string]interface{}
+	operationsIDS  map[string]struct{}
+	defaultResps   []*OperationResponse
+	dedupedSchemas map[string]string
+	errors         []error
+	warnings       []error
+	fullNames      bool
+	sortParams     bool
+	sortTags       bool
+	dedupe         bool
+	requireDescs   bool
+	autoOpIDs      bool
+	durationFormat DurationFormat
+	schemaPrefix   string
+	schemaSuffix   string
+	tagOrder       []string
+	contentType    string
+	arrayStyle     string
+	arrayExplode   bool
+	maxDepth       int
+	schemaDepth    int
+	finalized      bool
+	mu             sync.Mutex
 }
 
 // NewGenerator returns a new OpenAPI generator.
@@ -71,13 +163,18 @@ func NewGenerator(conf *SpecGenConfig) (*Generator, error) {
 			Paths:      make(Paths),
 			Components: components,
 		},
-		schemaTypes:   make(map[reflect.Type]struct{}),
-		typeNames:     make(map[reflect.Type]string),
-		dataTypes:     make(map[reflect.Type]*OverridedDataType),
-		operationsIDS: make(map[string]struct{}),
-		fullNames:     true,
-		sortParams:    true,
-		sortTags:      true,
+		schemaTypes:    make(map[reflect.Type]struct{}),
+		typeNames:      make(map[reflect.Type]string),
+		overrides:      make(map[reflect.Type]*Schema),
+		fieldOverrides: make(map[reflect.Type]map[string]*Schema),
+		interfaces:     make(map[reflect.Type]*interfaceImpls),
+		enums:          make(map[reflect.Type][]EnumValue),
+		schemaExts:     make(map[reflect.Type]map[string]interface{}),
+		operationsIDS:  make(map[string]struct{}),
+		dedupedSchemas: make(map[string]string),
+		fullNames:      true,
+		sortParams:     true,
+		sortTags:       true,
 	}, nil
 }
 
@@ -92,6 +189,7 @@ type SpecGenConfig struct {
 	QueryLocationTag  string
 	FormLocationTag   string
 	HeaderLocationTag string
+	CookieLocationTag string
 	EnumTag           string
 	DefaultTag        string
 }
@@ -102,12 +200,80 @@ func (g *Generator) SetInfo(info *Info) {
 	g.api.Info = info
 }
 
+// SetContact sets the contact information
+// of the API in the info of the specification.
+func (g *Generator) SetContact(name, url, email string) {
+	if g.api.Info == nil {
+		g.api.Info = &Info{}
+	}
+	g.api.Info.Contact = &Contact{
+		Name:  name,
+		URL:   url,
+		Email: email,
+	}
+}
+
+// SetLicense sets the license of the API
+// in the info of the specification.
+func (g *Generator) SetLicense(name, url string) {
+	if g.api.Info == nil {
+		g.api.Info = &Info{}
+	}
+	g.api.Info.License = &License{
+		Name: name,
+		URL:  url,
+	}
+}
+
+// SetTermsOfService sets the URL of the terms of
+// service of the API in the info of the specification.
+func (g *Generator) SetTermsOfService(url string) {
+	if g.api.Info == nil {
+		g.api.Info = &Info{}
+	}
+	g.api.Info.TermsOfService = url
+}
+
+// SetInfoSummary sets the short summary of the API in
+// the info of the specification. The summary is part of
+// the info since the version 3.1 of the specification.
+func (g *Generator) SetInfoSummary(summary string) {
+	if g.api.Info == nil {
+		g.api.Info = &Info{}
+	}
+	g.api.Info.Summary = summary
+}
+
+// SetOpenAPIVersion sets the version of the OpenAPI
+// specification. Both 3.0.x and 3.1.x versions are
+// supported. With a 3.1.x version, the nullable schemas
+// are described with a JSON Schema type array that
+// includes "null" instead of the nullable keyword.
+func (g *Generator) SetOpenAPIVersion(v string) error {
+	if !strings.HasPrefix(v, "3.0.") && !strings.HasPrefix(v, "3.1.") {
+		return fmt.Errorf("unsupported OpenAPI version %s", v)
+	}
+	g.api.OpenAPI = v
+	g.finalized = false
+
+	return nil
+}
+
 // SetServers sets the server list for the
 // current specification.
 func (g *Generator) SetServers(servers []*Server) {
 	g.api.Servers = servers
 }
 
+// SetExternalDocs sets the external documentation
+// of the specification.
+func (g *Generator) SetExternalDocs(url, description string) {
+	g.api.ExternalDocs = &ExternalDocumentation{
+		URL:         url,
+		Description: description,
+	}
+}
+
 // SetSecurityRequirement sets the security options for the
 // current specification.
 func (g *Generator) SetSecurityRequirement(security []*SecurityRequirement) {
@@ -120,18 +286,147 @@ func (g *Generator) SetSecuritySchemes(security map[string]*SecuritySchemeOrRef)
 	g.api.Components.SecuritySchemes = security
 }
 
+// SetGlobalSecurity sets the security requirements that
+// apply to all the operations of the specification, unless
+// they are overrided at the operation level.
+func (g *Generator) SetGlobalSecurity(requirements ...*SecurityRequirement) {
+	g.SetSecurityRequirement(requirements)
+}
+
+// AddSecurityScheme registers a security scheme with the
+// given name in the components of the specification. If a
+// scheme already exists with the same name, it is replaced.
+func (g *Generator) AddSecurityScheme(name string, scheme *SecurityScheme) error {
+	if name == "" {
+		return errors.New("security scheme name is empty")
+	}
+	if scheme == nil {
+		return errors.New("security scheme is nil")
+	}
+	switch scheme.Type {
+	case "apiKey":
+		if scheme.Name == "" {
+			return fmt.Errorf("security scheme %s: name is mandatory for type apiKey", name)
+		}
+		switch scheme.In {
+		case "header", "query", "cookie":
+		default:
+			return fmt.Errorf("security scheme %s: invalid location %q for type apiKey", name, scheme.In)
+		}
+	case "http":
+		if scheme.Scheme == "" {
+			return fmt.Errorf("security scheme %s: scheme is mandatory for type http", name)
+		}
+	case "oauth2":
+		if scheme.Flows == nil {
+			return fmt.Errorf("security scheme %s: flows are mandatory for type oauth2", name)
+		}
+	case "openIdConnect":
+		if scheme.OpenIDConnectURL == "" {
+			return fmt.Errorf("security scheme %s: URL is mandatory for type openIdConnect", name)
+		}
+	default:
+		return fmt.Errorf("security scheme %s: unknown type %q", name, scheme.Type)
+	}
+	if g.api.Components.SecuritySchemes == nil {
+		g.api.Components.SecuritySchemes = make(map[string]*SecuritySchemeOrRef)
+	}
+	g.api.Components.SecuritySchemes[name] = &SecuritySchemeOrRef{
+		SecurityScheme: scheme,
+	}
+	return nil
+}
+
+// AddComponentExample registers an example with the given
+// name in the components of the specification, which can be
+// referenced by the examples of the operations. If an example
+// already exists with the same name, it is replaced.
+func (g *Generator) AddComponentExample(name string, example *Example) error {
+	if name == "" {
+		return errors.New("example name is empty")
+	}
+	if example == nil {
+		return errors.New("example is nil")
+	}
+	if g.api.Components.Examples == nil {
+		g.api.Components.Examples = make(map[string]*ExampleOrRef)
+	}
+	g.api.Components.Examples[name] = &ExampleOrRef{Example: example}
+
+	return nil
+}
+
 // API returns a copy of the internal OpenAPI object.
 func (g *Generator) API() *OpenAPI {
+	g.finalize()
+
 	cpy := *g.api
 	return &cpy
 }
 
+// finalize applies the settings that depend on the whole
+// specification to its schemas, such as the deduplication
+// of the inlined schemas and the version used to marshal
+// them. It runs only once until the specification
+// is modified, so that the readers of the specification that
+// run concurrently, like the handlers of the routes, do not
+// write to the shared schemas.
+func (g *Generator) finalize() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.finalized {
+		return
+	}
+	if g.dedupe {
+		g.dedupeSchemas()
+	}
+	v31 := strings.HasPrefix(g.api.OpenAPI, "3.1.")
+	walkSchemas(g.api, func(s *Schema) {
+		s.v31 = v31
+	})
+	g.finalized = true
+}
+
 // Errors returns the errors thar occurred during
 // the generation of the specification.
 func (g *Generator) Errors() []error {
 	return g.errors
 }
 
+// Warnings returns the problems that were resolved
+// by the generator, such as the renamed operations.
+func (g *Generator) Warnings() []error {
+	return g.warnings
+}
+
+// SchemaNames returns the sorted names of
+// the component schemas of the specification.
+func (g *Generator) SchemaNames() []string {
+	schemas := g.API().Components.Schemas
+
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// SchemaByName returns the component schema with
+// the given name, and whether it exists. A component
+// that references another component is resolved.
+func (g *Generator) SchemaByName(name string) (*Schema, bool) {
+	sor, ok := g.API().Components.Schemas[name]
+	if !ok || sor == nil {
+		return nil, false
+	}
+	s := g.resolveSchema(sor)
+
+	return s, s != nil
+}
+
 // UseFullSchemaNames defines whether the generator should generates
 // a full name for the components using the package name of the type
 // as a prefix.
@@ -143,6 +438,51 @@ func (g *Generator) UseFullSchemaNames(b bool) {
 	g.fullNames = b
 }
 
+// SetSchemaNamePrefix sets a prefix that is added to the
+// names of the component schemas generated afterward, and
+// to their references, such as to avoid conflicts between
+// the components of the specifications that are merged.
+func (g *Generator) SetSchemaNamePrefix(prefix string) {
+	g.schemaPrefix = prefix
+}
+
+// SetSchemaNameSuffix sets a suffix that is added to the
+// names of the component schemas generated afterward, and
+// to their references.
+func (g *Generator) SetSchemaNameSuffix(suffix string) {
+	g.schemaSuffix = suffix
+}
+
+// SetDefaultContentType sets the media type of the request
+// and response bodies of the operations that don't specify
+// one, instead of the default media type of tonic, such as
+// application/vnd.api+json.
+func (g *Generator) SetDefaultContentType(ct string) {
+	g.contentType = ct
+}
+
+// DefaultContentType returns the media type of the
+// request and response bodies of the operations that
+// don't specify one.
+func (g *Generator) DefaultContentType() string {
+	if g.contentType != "" {
+		return g.contentType
+	}
+	return tonic.MediaType()
+}
+
+// SetArrayQueryStyle sets the style and the explode
+// property of the query parameters of type array that
+// don't have a style tag, instead of the exploded form.
+func (g *Generator) SetArrayQueryStyle(style string, explode bool) error {
+	if style == "deepObject" || !containsString(parameterStyles["query"], style) {
+		return fmt.Errorf("style %s cannot be applied to an array query parameter", style)
+	}
+	g.arrayStyle, g.arrayExplode = style, explode
+
+	return nil
+}
+
 // SetSortParams controls whether the generator should
 // sort the parameters of an operation by location and
 // name in ascending order.
@@ -156,6 +496,140 @@ func (g *Generator) SetSortTags(b bool) {
 	g.sortTags = b
 }
 
+// SetTagOrder sets the order of the global tags sections.
+// The tags with the given names come first, in that order,
+// and the others follow, sorted by name if the generator
+// sorts the tags.
+func (g *Generator) SetTagOrder(names ...string) {
+	g.tagOrder = append([]string(nil), names...)
+	g.sortAPITags()
+}
+
+// SetRequireResponseDescriptions controls whether the
+// generator should record an error for the responses
+// of the operations that have no explicit description,
+// instead of using the text of their status code.
+func (g *Generator) SetRequireResponseDescriptions(b bool) {
+	g.requireDescs = b
+}
+
+// SetAutoResolveOperationIDs controls whether the generator
+// should rename the operations whose ID is already used by
+// another operation, by suffixing a numeric counter to the
+// ID, instead of returning an error. The renamed operations
+// are reported in the warnings of the generator.
+func (g *Generator) SetAutoResolveOperationIDs(b bool) {
+	g.autoOpIDs = b
+}
+
+// DurationFormat represents the encoding of
+// the time.Duration values of the API.
+type DurationFormat int
+
+// Duration formats.
+const (
+	// DurationString describes the durations as strings
+	// with the duration format, like time.Duration.String.
+	DurationString DurationFormat = iota
+	// DurationNanos describes the durations as
+	// integers, which are numbers of nanoseconds.
+	DurationNanos
+	// DurationSeconds describes the durations as
+	// integers, which are numbers of seconds.
+	DurationSeconds
+)
+
+// SetDurationFormat sets the encoding of the time.Duration
+// values, which are described as strings by default. Use
+// DurationNanos or DurationSeconds if the durations are
+// marshaled as integers instead.
+func (g *Generator) SetDurationFormat(f DurationFormat) {
+	g.durationFormat = f
+}
+
+// SetDefaultResponse sets a response that is added to every
+// operation that doesn't define a response with the same code,
+// including the operations added afterward. The response model
+// is described for the default media type of tonic.
+func (g *Generator) SetDefaultResponse(code, description string, model interface{}) error {
+	if _, err := responseStatusCode(code); err != nil {
+		return err
+	}
+	resp := &OperationResponse{
+		Code:        code,
+		Description: description,
+		Model:       model,
+	}
+	var replaced bool
+	for i, r := range g.defaultResps {
+		if r.Code == code {
+			g.defaultResps[i] = resp
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		g.defaultResps = append(g.defaultResps, resp)
+	}
+	// The operations added beforehand get the
+	// response if they don't define the code.
+	for _, op := range g.api.Paths.operations() {
+		g.setDefaultResponses(op, resp)
+	}
+	return nil
+}
+
+// AddRangeResponse sets a response for a range of status
+// codes, from 1XX to 5XX, that is added to every operation
+// like the responses of SetDefaultResponse. An operation
+// overrides it by declaring a response with the same range.
+func (g *Generator) AddRangeResponse(codeRange, description string, model interface{}) error {
+	if !isResponseCodeRange(codeRange) {
+		return fmt.Errorf("invalid response code range: %s", codeRange)
+	}
+	return g.SetDefaultResponse(codeRange, description, model)
+}
+
+// setDefaultResponses adds the default responses
+// resps to the operation op if it doesn't define
+// responses with the same codes.
+func (g *Generator) setDefaultResponses(op *Operation, resps ...*OperationResponse) {
+	if len(resps) == 0 {
+		return
+	}
+	if op.Responses == nil {
+		op.Responses = make(Responses)
+	}
+	for _, r := range resps {
+		if _, ok := op.Responses[r.Code]; ok {
+			continue
+		}
+		if err := g.setOperationResponse(op, reflect.TypeOf(r.Model), r.Code, g.DefaultContentType(), r.Description, nil, nil, nil); err != nil {
+			g.error(err)
+		}
+	}
+}
+
+// SetDedupeSchemas controls whether the generator should
+// move the inlined schemas of anonymous structs that are
+// identical into the components of the specification,
+// and replace them with references to a shared component.
+// Default to false.
+func (g *Generator) SetDedupeSchemas(b bool) {
+	g.dedupe = b
+	g.finalized = false
+}
+
+// SetMaxSchemaDepth sets the maximum number of nested
+// schemas of anonymous structs that are inlined in the
+// schema of a component or an operation. The schemas that
+// are nested deeper are moved into the components of the
+// specification, like the deduplicated schemas, and replaced
+// with a reference. Default to 0, which means unlimited.
+func (g *Generator) SetMaxSchemaDepth(n int) {
+	g.maxDepth = n
+}
+
 // OverrideTypeName registers a custom name for a
 // type that will override the default generation
 // and have precedence over types that implements
@@ -182,22 +656,223 @@ func (g *Generator) OverrideDataType(t reflect.Type, typ, format string) error {
 	if typ == "" {
 		return errors.New("type is mandatory")
 	}
+	return g.OverrideSchema(t, &Schema{
+		Type:   typ,
+		Format: format,
+	})
+}
+
+// OverrideSchema registers a custom schema for the given
+// type that will be used verbatim instead of the default
+// generation. Each occurrence of the type is described by
+// a copy of the schema.
+func (g *Generator) OverrideSchema(t reflect.Type, schema *Schema) error {
+	if schema == nil {
+		return errors.New("schema is mandatory")
+	}
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
-	if _, ok := g.dataTypes[t]; ok {
+	if _, ok := g.overrides[t]; ok {
 		return errors.New("data type already overrided")
 	}
-	g.dataTypes[t] = &OverridedDataType{
-		format: format,
-		typ:    typ,
+	g.overrides[t] = schema
+
+	return nil
+}
+
+// OverrideFieldSchema registers a custom schema for the
+// field with the given name, declared by the struct type t,
+// that will be used instead of the schema of the type of
+// the field, which is left unchanged for the other fields.
+// The tags of the field are applied to a copy of the schema.
+func (g *Generator) OverrideFieldSchema(t reflect.Type, fieldName string, schema *Schema) error {
+	if schema == nil {
+		return errors.New("schema is mandatory")
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("type %s is not a struct", t)
+	}
+	if f, ok := t.FieldByName(fieldName); !ok || len(f.Index) != 1 {
+		return fmt.Errorf("struct %s has no field %s", t, fieldName)
+	}
+	if _, ok := g.fieldOverrides[t][fieldName]; ok {
+		return errors.New("field schema already overrided")
+	}
+	if g.fieldOverrides[t] == nil {
+		g.fieldOverrides[t] = make(map[string]*Schema)
+	}
+	g.fieldOverrides[t][fieldName] = schema
+
+	return nil
+}
+
+// overrideSchema returns a copy of the custom schema
+// registered for the given type, or nil if the type
+// has no override.
+func (g *Generator) overrideSchema(t reflect.Type) *Schema {
+	s, ok := g.overrides[t]
+	if !ok {
+		return nil
+	}
+	cpy := *s
+	return &cpy
+}
+
+// interfaceImpls represents the concrete types
+// registered for an interface type.
+type interfaceImpls struct {
+	types         []reflect.Type
+	discriminator string
+	mapping       map[string]reflect.Type
+}
+
+// RegisterInterfaceImplementations registers the concrete
+// types that implement the interface type iface. The schema
+// of a value of this interface will be described as one of
+// the schemas of the implementations, instead of a value of
+// any type.
+func (g *Generator) RegisterInterfaceImplementations(iface reflect.Type, impls ...reflect.Type) error {
+	if iface.Kind() == reflect.Ptr {
+		iface = iface.Elem()
+	}
+	if iface.Kind() != reflect.Interface {
+		return fmt.Errorf("type %s is not an interface", iface)
+	}
+	if len(impls) == 0 {
+		return errors.New("no implementations")
 	}
+	for _, t := range impls {
+		if !t.Implements(iface) && !reflect.PtrTo(t).Implements(iface) {
+			return fmt.Errorf("type %s does not implement interface %s", t, iface)
+		}
+	}
+	ii, ok := g.interfaces[iface]
+	if !ok {
+		ii = &interfaceImpls{}
+		g.interfaces[iface] = ii
+	}
+	ii.types = append(ii.types, impls...)
+
+	return nil
+}
+
+// SetInterfaceDiscriminator sets the name of the property
+// that is used to differentiate between the implementations
+// registered for the interface type iface.
+func (g *Generator) SetInterfaceDiscriminator(iface reflect.Type, propertyName string) error {
+	if iface.Kind() == reflect.Ptr {
+		iface = iface.Elem()
+	}
+	if propertyName == "" {
+		return errors.New("property name is empty")
+	}
+	ii, ok := g.interfaces[iface]
+	if !ok {
+		return fmt.Errorf("no implementations registered for interface %s", iface)
+	}
+	ii.discriminator = propertyName
+
+	return nil
+}
+
+// AddInterfaceDiscriminatorMapping maps a value of the
+// discriminator property of the interface type iface to
+// the schema of one of its registered implementations,
+// which must be described by a component schema.
+func (g *Generator) AddInterfaceDiscriminatorMapping(iface reflect.Type, value string, impl reflect.Type) error {
+	if iface.Kind() == reflect.Ptr {
+		iface = iface.Elem()
+	}
+	if value == "" {
+		return errors.New("discriminator value is empty")
+	}
+	ii, ok := g.interfaces[iface]
+	if !ok {
+		return fmt.Errorf("no implementations registered for interface %s", iface)
+	}
+	if ii.discriminator == "" {
+		return fmt.Errorf("no discriminator set for interface %s", iface)
+	}
+	var registered bool
+	for _, t := range ii.types {
+		if t == impl {
+			registered = true
+			break
+		}
+	}
+	if !registered {
+		return fmt.Errorf("type %s is not a registered implementation of interface %s", impl, iface)
+	}
+	if ii.mapping == nil {
+		ii.mapping = make(map[string]reflect.Type)
+	}
+	ii.mapping[value] = impl
+
+	return nil
+}
+
+// AddSchemaExtension adds a specification extension,
+// whose name must start with x-, to the component schema
+// of the struct type t. It must be called before the
+// schema of the type is generated.
+func (g *Generator) AddSchemaExtension(t reflect.Type, key string, value interface{}) error {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("type %s is not a struct", t)
+	}
+	if err := validateExtension(key); err != nil {
+		return err
+	}
+	if _, ok := g.schemaExts[t]; !ok {
+		g.schemaExts[t] = make(map[string]interface{})
+	}
+	g.schemaExts[t][key] = value
+
+	return nil
+}
+
+// RegisterEnum registers the values of the enum type t.
+// The schema of a struct field of this type lists the
+// values, and their names in the x-enum-varnames extension,
+// unless the field has an enum tag.
+func (g *Generator) RegisterEnum(t reflect.Type, values []EnumValue) error {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if len(values) == 0 {
+		return errors.New("no enum values")
+	}
+	if _, ok := g.enums[t]; ok {
+		return fmt.Errorf("enum values already registered for type %s", t)
+	}
+	for _, v := range values {
+		if v.Value == nil || !reflect.TypeOf(v.Value).ConvertibleTo(t) {
+			return fmt.Errorf("enum value %v of %s cannot be converted to type %s", v.Value, v.Name, t)
+		}
+		if v.Name == "" {
+			return fmt.Errorf("enum value %v of type %s has no name", v.Value, t)
+		}
+	}
+	g.enums[t] = values
+
 	return nil
 }
 
 func (g *Generator) datatype(t reflect.Type) DataType {
-	if dt, ok := g.dataTypes[t]; ok {
-		return dt
+	if s, ok := g.overrides[t]; ok {
+		return &OverridedDataType{
+			format: s.Format,
+			typ:    s.Type,
+		}
+	}
+	if t == tofDuration && g.durationFormat != DurationString {
+		return TypeLong
 	}
 	return DataTypeFromType(t)
 }
@@ -225,14 +900,49 @@ func (g *Generator) AddTag(name, desc string) {
 		Name:        name,
 		Description: desc,
 	})
-	if g.sortTags {
-		sort.SliceStable(g.api.Tags, func(i, j int) bool {
-			if g.api.Tags[i] != nil && g.api.Tags[j] != nil {
-				return g.api.Tags[i].Name < g.api.Tags[j].Name
+	g.sortAPITags()
+}
+
+// TagByName returns the tag of the specification
+// with the given name, and whether it exists.
+func (g *Generator) TagByName(name string) (*Tag, bool) {
+	for _, tag := range g.api.Tags {
+		if tag != nil && tag.Name == name {
+			return tag, true
+		}
+	}
+	return nil, false
+}
+
+// sortAPITags sorts the global tags of the spec. The
+// tags of the custom order come first, in that order,
+// followed by the others in ascending order, if the
+// tags are sorted, or in the order they were added.
+func (g *Generator) sortAPITags() {
+	if !g.sortTags && len(g.tagOrder) == 0 {
+		return
+	}
+	rank := func(t *Tag) int {
+		for i, name := range g.tagOrder {
+			if t.Name == name {
+				return i
 			}
-			return false
-		})
+		}
+		return len(g.tagOrder)
 	}
+	sort.SliceStable(g.api.Tags, func(i, j int) bool {
+		ti, tj := g.api.Tags[i], g.api.Tags[j]
+		if ti == nil || tj == nil {
+			return false
+		}
+		if ri, rj := rank(ti), rank(tj); ri != rj {
+			return ri < rj
+		}
+		if g.sortTags {
+			return ti.Name < tj.Name
+		}
+		return false
+	})
 }
 
 // AddOperation add a new operation to the OpenAPI specification
@@ -243,13 +953,30 @@ func (g *Generator) AddOperation(path, method, tag, requestMediaType, responseMe
 		ID: uuid.Must(uuid.NewV4()).String(),
 	}
 	path = rewritePath(path)
+	g.finalized = false
 
 	if info != nil {
 		// Ensure that the provided operation ID is unique.
 		if _, ok := g.operationsIDS[info.ID]; ok {
-			return nil, fmt.Errorf("ID %s is already used by another operation", info.ID)
+			if !g.autoOpIDs {
+				return nil, fmt.Errorf("ID %s is already used by another operation", info.ID)
+			}
+			id := g.uniqueOperationID(info.ID)
+			g.warnings = append(g.warnings, fmt.Errorf(
+				"ID %s of operation %s %s is already used by another operation, renamed to %s",
+				info.ID, method, path, id,
+			))
+			op.ID = id
+		} else {
+			op.ID = info.ID
+		}
+		g.operationsIDS[op.ID] = struct{}{}
+
+		// The media type of the operation
+		// has precedence over the given one.
+		if info.InputMediaType != "" {
+			requestMediaType = info.InputMediaType
 		}
-		g.operationsIDS[info.ID] = struct{}{}
 	}
 	// If a PathItem does not exists for this
 	// path, create a new one.
@@ -261,16 +988,34 @@ func (g *Generator) AddOperation(path, method, tag, requestMediaType, responseMe
 	// Create a new operation and set it
 	// to the according method of the PathItem.
 	if info != nil {
-		op.ID = info.ID
 		op.Summary = info.Summary
 		op.Description = info.Description
+		op.ExternalDocs = info.ExternalDocs
+		op.Servers = info.Servers
 		op.Deprecated = info.Deprecated
 		op.Responses = make(Responses)
 		op.XCodeSamples = info.XCodeSamples
+		op.Callbacks = info.Callbacks
 		op.Security = info.Security
 		op.XInternal = info.XInternal
+
+		for k, v := range info.Extensions {
+			if err := validateExtension(k); err != nil {
+				return nil, err
+			}
+			if op.Extensions == nil {
+				op.Extensions = make(map[string]interface{}, len(info.Extensions))
+			}
+			op.Extensions[k] = v
+		}
+
+		for _, t := range info.Tags {
+			if t != "" && !containsString(op.Tags, t) {
+				op.Tags = append(op.Tags, t)
+			}
+		}
 	}
-	if tag != "" {
+	if tag != "" && !containsString(op.Tags, tag) {
 		op.Tags = append(op.Tags, tag)
 	}
 	// Operations with methods GET/HEAD cannot have a body.
@@ -278,6 +1023,17 @@ func (g *Generator) AddOperation(path, method, tag, requestMediaType, responseMe
 	allowBody := method != http.MethodGet &&
 		method != http.MethodHead
 
+	// The schema of the input replaces the body
+	// described by the fields of the input type.
+	if info != nil && info.InputSchema != nil {
+		if info.InputModel != nil {
+			return nil, errors.New("input model and input schema are mutually exclusive")
+		}
+		if !allowBody {
+			return nil, fmt.Errorf("input schema cannot be set for an operation with method %s", method)
+		}
+		allowBody = false
+	}
 	if in != nil {
 		if in.Kind() == reflect.Ptr {
 			in = in.Elem()
@@ -289,12 +1045,35 @@ func (g *Generator) AddOperation(path, method, tag, requestMediaType, responseMe
 			return nil, err
 		}
 	}
+	if info != nil && info.InputSchema != nil {
+		mt := requestMediaType
+		if mt == "" {
+			mt = anyMediaType
+		}
+		op.RequestBody = &RequestBody{
+			Content: map[string]*MediaType{
+				mt: {Schema: &SchemaOrRef{Schema: info.InputSchema}},
+			},
+		}
+	}
+	if err := setRequestBodyExamples(op, info.RequestExample, info.RequestExamples); err != nil {
+		return nil, err
+	}
+	if info.BodyRequired != nil {
+		if op.RequestBody == nil {
+			return nil, errors.New("request body cannot be required without a request body")
+		}
+		op.RequestBody.Required = *info.BodyRequired
+	}
 	// Generate the default response from the tonic
 	// handler return type. If the handler has no output
 	// type, the response won't have a schema.
 	if err := g.setOperationResponse(op, out, strconv.Itoa(info.StatusCode), responseMediaType, info.StatusDescription, info.Headers, nil, nil); err != nil {
 		return nil, err
 	}
+	// The default response may document additional
+	// media types, with their own models.
+	g.setResponseContents(op.Responses[strconv.Itoa(info.StatusCode)].Response, info.StatusContents)
 	// Generate additional responses from the operation
 	// informations.
 	for _, resp := range info.Responses {
@@ -310,7 +1089,32 @@ func (g *Generator) AddOperation(path, method, tag, requestMediaType, responseMe
 			); err != nil {
 				return nil, err
 			}
+			g.setResponseContents(op.Responses[resp.Code].Response, resp.Contents)
+		}
+	}
+	// Add the named examples to the responses.
+	for code, examples := range info.ResponseExamples {
+		if err := setResponseExamples(op, code, examples); err != nil {
+			return nil, err
+		}
+	}
+	// Add the default responses of the codes
+	// that the operation doesn't define.
+	g.setDefaultResponses(op, g.defaultResps...)
+
+	// Add the links to the responses.
+	for _, l := range info.Links {
+		if l == nil {
+			continue
+		}
+		resp, ok := op.Responses[l.Code]
+		if !ok || resp.Response == nil {
+			return nil, fmt.Errorf("link %s refers to a response with code %s that does not exist", l.Name, l.Code)
 		}
+		if resp.Links == nil {
+			resp.Links = make(map[string]*Link)
+		}
+		resp.Links[l.Name] = l.Link
 	}
 	setOperationBymethod(item, op, method)
 
@@ -347,6 +1151,37 @@ func setOperationBymethod(item *PathItem, op *Operation, method string) {
 	}
 }
 
+// responseStatusCode checks that the response code is valid
+// per the spec and returns the corresponding HTTP status code,
+// or zero for the default response and the ranges of codes.
+// https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.2.md#patterned-fields-1
+func responseStatusCode(code string) (int, error) {
+	if code == "default" || isResponseCodeRange(code) {
+		return 0, nil
+	}
+	// Convert code to number and check that it is
+	// between 100 and 599.
+	ci, err := strconv.Atoi(code)
+	if err != nil {
+		return 0, fmt.Errorf("invalid response code: %s", err)
+	}
+	if ci < 100 || ci > 599 {
+		return 0, fmt.Errorf("response code out of range: %s", code)
+	}
+	return ci, nil
+}
+
+// containsString returns whether the
+// slice contains the given string.
+func containsString(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 func isResponseCodeRange(code string) bool {
 	if len(code) != 3 {
 		return false
@@ -363,6 +1198,17 @@ func isResponseCodeRange(code string) bool {
 	return true
 }
 
+// uniqueOperationID returns the ID suffixed with the
+// first counter that makes it unique, starting at 2.
+func (g *Generator) uniqueOperationID(id string) string {
+	for i := 2; ; i++ {
+		s := fmt.Sprintf("%s_%d", id, i)
+		if _, ok := g.operationsIDS[s]; !ok {
+			return s
+		}
+	}
+}
+
 // setOperationResponse adds a response to the operation that
 // return the type t with the given media type and status code.
 func (g *Generator) setOperationResponse(op *Operation, t reflect.Type, code, mt, desc string, headers []*ResponseHeader, example interface{}, examples map[string]interface{}) error {
@@ -375,23 +1221,15 @@ func (g *Generator) setOperationResponse(op *Operation, t reflect.Type, code, mt
 		return fmt.Errorf("'example' and 'examples' are mutually exclusive")
 	}
 
-	// Check that the response code is valid per the spec:
-	// https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.2.md#patterned-fields-1
-	if code != "default" {
-		if !isResponseCodeRange(code) { // ignore ranges
-			// Convert code to number and check that it is
-			// between 100 and 599.
-			ci, err := strconv.Atoi(code)
-			if err != nil {
-				return fmt.Errorf("invalid response code: %s", err)
-			}
-			if ci < 100 || ci > 599 {
-				return fmt.Errorf("response code out of range: %s", code)
-			}
-			if desc == "" {
-				desc = http.StatusText(ci)
-			}
-		}
+	ci, err := responseStatusCode(code)
+	if err != nil {
+		return err
+	}
+	if desc == "" && g.requireDescs {
+		g.error(fmt.Errorf("missing description of the response with code %s of the operation %s", code, op.ID))
+	}
+	if ci != 0 && desc == "" {
+		desc = http.StatusText(ci)
 	}
 	r := &Response{
 		Description: desc,
@@ -403,7 +1241,7 @@ func (g *Generator) setOperationResponse(op *Operation, t reflect.Type, code, mt
 	if examples != nil {
 		castedExamples = make(map[string]*ExampleOrRef)
 		for name, val := range examples {
-			castedExamples[name] = &ExampleOrRef{Example: &Example{Value: val}}
+			castedExamples[name] = newExampleOrRef(val)
 		}
 	}
 
@@ -430,7 +1268,10 @@ func (g *Generator) setOperationResponse(op *Operation, t reflect.Type, code, mt
 			}
 			r.Headers[h.Name] = &HeaderOrRef{Header: &Header{
 				Description: h.Description,
+				Required:    h.Required,
+				Deprecated:  h.Deprecated,
 				Schema:      sor,
+				Example:     h.Example,
 			}}
 		}
 	}
@@ -439,6 +1280,85 @@ func (g *Generator) setOperationResponse(op *Operation, t reflect.Type, code, mt
 	return nil
 }
 
+// setRequestBodyExamples sets the example, or the named
+// examples, of the media types of the operation request body.
+func setRequestBodyExamples(op *Operation, example interface{}, examples map[string]interface{}) error {
+	if example == nil && examples == nil {
+		return nil
+	}
+	if example != nil && examples != nil {
+		// Cannot set both 'example' and 'examples' values
+		return fmt.Errorf("'example' and 'examples' are mutually exclusive")
+	}
+	if op.RequestBody == nil {
+		return errors.New("request examples cannot be set without a request body")
+	}
+	for _, mt := range op.RequestBody.Content {
+		if mt == nil {
+			continue
+		}
+		mt.Example = example
+
+		if examples != nil {
+			mt.Examples = make(map[string]*ExampleOrRef, len(examples))
+			for name, val := range examples {
+				mt.Examples[name] = newExampleOrRef(val)
+			}
+		}
+	}
+	return nil
+}
+
+// setResponseExamples adds the named examples to each
+// media type of the response of the operation with the
+// given code, which must have been declared before.
+func setResponseExamples(op *Operation, code string, examples map[string]interface{}) error {
+	resp, ok := op.Responses[code]
+	if !ok || resp.Response == nil {
+		return fmt.Errorf("response examples refer to a response with code %s that does not exist", code)
+	}
+	if len(resp.Content) == 0 {
+		return fmt.Errorf("response examples cannot be set for the response with code %s without content", code)
+	}
+	for _, mt := range resp.Content {
+		if mt == nil || mt.MediaType == nil {
+			continue
+		}
+		if mt.Example != nil {
+			// Cannot set both 'example' and 'examples' values
+			return fmt.Errorf("'example' and 'examples' are mutually exclusive")
+		}
+		if mt.Examples == nil {
+			mt.Examples = make(map[string]*ExampleOrRef, len(examples))
+		}
+		for name, val := range examples {
+			mt.Examples[name] = newExampleOrRef(val)
+		}
+	}
+	return nil
+}
+
+// newExampleOrRef returns a reference to a component
+// example if val is a reference, or an inlined example
+// with the value val.
+func newExampleOrRef(val interface{}) *ExampleOrRef {
+	if ref, ok := val.(*Reference); ok {
+		return &ExampleOrRef{Reference: ref}
+	}
+	return &ExampleOrRef{Example: &Example{Value: val}}
+}
+
+// setResponseContents adds a content to the response for
+// each media type of contents, described by the schema of
+// the associated model.
+func (g *Generator) setResponseContents(r *Response, contents map[string]interface{}) {
+	for mt, model := range contents {
+		r.Content[mt] = &MediaTypeOrRef{MediaType: &MediaType{
+			Schema: g.newSchemaFromType(reflect.TypeOf(model), mt),
+		}}
+	}
+}
+
 // setOperationParams adds the fields of the struct type t
 // to the given operation.
 func (g *Generator) setOperationParams(op *Operation, t, parent reflect.Type, allowBody bool, path string, requestMediaType string) error {
@@ -461,7 +1381,7 @@ func (g *Generator) setOperationParams(op *Operation, t, parent reflect.Type, al
 		}
 		sch := op.RequestBody.Content[mt].Schema
 		if sch != nil && !isMultipartFormData(requestMediaType) {
-			name := strings.Title(op.ID) + "Input"
+			name := g.componentName(strings.Title(op.ID) + "Input")
 			g.api.Components.Schemas[name] = sch
 			op.RequestBody.Content[mt].Schema = &SchemaOrRef{Reference: &Reference{
 				Ref: componentsSchemaPath + name,
@@ -561,6 +1481,15 @@ func (g *Generator) paramyByLocation(p1, p2 *ParameterOrRef) bool {
 func (g *Generator) addStructFieldToOperation(op *Operation, t reflect.Type, idx int, allowBody bool, requestMediaType string) error {
 	sf := t.Field(idx)
 
+	// If binding is disabled for this field, don't add
+	// it to the parameters nor to the request body. This
+	// allow using a model type as an operation input while
+	// also omitting some fields that are computed by the
+	// server.
+	if g.isFieldBindingDisabled(sf) {
+		return nil
+	}
+
 	param, location, err := g.newParameterFromField(idx, t, requestMediaType)
 	if err != nil {
 		return err
@@ -615,14 +1544,6 @@ func (g *Generator) addStructFieldToOperation(op *Operation, t reflect.Type, idx
 		if !allowBody {
 			return nil
 		}
-		// If binding is disabled for this field, don't
-		// add it to the request body. This allow using
-		// a model type as an operation input while also
-		// omitting some fields that are computed by the
-		// server.
-		if sf.Tag.Get("binding") == "-" {
-			return nil
-		}
 		// The field is not a parameter, add it to
 		// the request body.
 		if op.RequestBody == nil {
@@ -651,13 +1572,10 @@ func (g *Generator) addStructFieldToOperation(op *Operation, t reflect.Type, idx
 		} else {
 			schema = op.RequestBody.Content[mt].Schema.Schema
 		}
-		fname := fieldNameFromTag(sf, mediaTags[requestMediaType])
+		fname := fieldNameFromTag(sf, mediaTagName(requestMediaType))
 
 		// Check if a field with the same name already exists.
 		if _, ok := schema.Properties[fname]; ok {
-			jsss, _ := json.Marshal(schema.Properties)
-			_ = os.WriteFile(fmt.Sprintf("d:\\test_%s.txt", fname), jsss, 0644)
-
 			g.error(&FieldError{
 				Message:           "duplicate request body parameter",
 				Name:              fname,
@@ -671,11 +1589,13 @@ func (g *Generator) addStructFieldToOperation(op *Operation, t reflect.Type, idx
 		var required bool
 		// The required property of a field is not part of its
 		// own schema but specified in the parent schema.
-		if fname != "" && g.isStructFieldRequired(sf) {
+		if fname != "" && g.isSchemaPropertyRequired(sf, mediaTagName(requestMediaType)) {
 			required = true
 			schema.Required = append(schema.Required, fname)
 			sort.Strings(schema.Required)
 		}
+		schema.RequiredConditions = append(schema.RequiredConditions, g.requiredConditions(sf, t, fname, mediaTagName(requestMediaType))...)
+
 		sfs := g.newSchemaFromStructField(sf, required, fname, t, requestMediaType)
 		if schema != nil {
 			schema.Properties[fname] = sfs
@@ -684,6 +1604,30 @@ func (g *Generator) addStructFieldToOperation(op *Operation, t reflect.Type, idx
 	return nil
 }
 
+// isFieldBindingDisabled returns whether the binding of
+// the struct field is disabled, either with the binding
+// tag or with the "-" name in one of its location tags.
+func (g *Generator) isFieldBindingDisabled(sf reflect.StructField) bool {
+	if sf.Tag.Get(bindingTag) == "-" {
+		return true
+	}
+	for _, loc := range []string{
+		g.config.PathLocationTag,
+		g.config.QueryLocationTag,
+		g.config.HeaderLocationTag,
+		g.config.CookieLocationTag,
+		g.config.FormLocationTag,
+	} {
+		if loc == "" {
+			continue
+		}
+		if v, ok := sf.Tag.Lookup(loc); ok && strings.TrimSpace(strings.Split(v, ",")[0]) == "-" {
+			return true
+		}
+	}
+	return false
+}
+
 // newParameterFromField create a new operation parameter
 // from the struct field at index idx in type in. Only the
 // parameters of type path, query, header or cookie are concerned.
@@ -697,12 +1641,14 @@ func (g *Generator) newParameterFromField(idx int, t reflect.Type, mediaType str
 			g.config.FormLocationTag,
 			g.config.QueryLocationTag,
 			g.config.HeaderLocationTag,
+			g.config.CookieLocationTag,
 		}
 	} else {
 		parameterLocations = []string{
 			g.config.PathLocationTag,
 			g.config.QueryLocationTag,
 			g.config.HeaderLocationTag,
+			g.config.CookieLocationTag,
 		}
 	}
 
@@ -719,6 +1665,9 @@ func (g *Generator) newParameterFromField(idx int, t reflect.Type, mediaType str
 	if err != nil {
 		return nil, location, err
 	}
+	// The location tags of the config may not be
+	// named after the locations of the parameters.
+	in := g.parameterIn(location)
 	required := g.isStructFieldRequired(field)
 
 	// Path parameters are always required.
@@ -730,7 +1679,7 @@ func (g *Generator) newParameterFromField(idx int, t reflect.Type, mediaType str
 
 	p := &Parameter{
 		Name:        name,
-		In:          location,
+		In:          in,
 		Description: field.Tag.Get(descriptionTag),
 		Required:    required,
 		Deprecated:  deprecated,
@@ -742,16 +1691,102 @@ func (g *Generator) newParameterFromField(idx int, t reflect.Type, mediaType str
 	// Style.
 	if location == g.config.QueryLocationTag {
 		if field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Array {
-			p.Explode = true // default
-			p.Style = "form" // default in spec, but make it obvious
-			if t := field.Tag.Get(tonic.ExplodeTag); t != "" {
-				if explode, err := strconv.ParseBool(t); err == nil && !explode { // ignore invalid values
-					p.Explode = explode
-				}
+			if g.arrayStyle != "" {
+				p.Style, p.Explode = g.arrayStyle, g.arrayExplode
+			} else {
+				p.Explode = true // default
+				p.Style = "form" // default in spec, but make it obvious
 			}
 		}
 	}
-	return p, location, nil
+	if style, ok := field.Tag.Lookup(styleTag); ok {
+		if style == "deepObject" && !isObjectType(field.Type) {
+			g.error(&FieldError{
+				Message:           "style deepObject can only be applied to a parameter of type struct or map",
+				Name:              name,
+				Type:              field.Type,
+				TypeName:          g.typeName(field.Type),
+				Parent:            t,
+				ParameterLocation: in,
+			})
+		} else if containsString(parameterStyles[in], style) {
+			// Only the form and deepObject styles
+			// are exploded by default.
+			p.Style = style
+			p.Explode = style == "form" || style == "deepObject"
+		} else {
+			g.error(&FieldError{
+				Message:           fmt.Sprintf("style %s cannot be applied to a parameter located in %s", style, in),
+				Name:              name,
+				Type:              field.Type,
+				TypeName:          g.typeName(field.Type),
+				Parent:            t,
+				ParameterLocation: in,
+			})
+		}
+	}
+	if p.Style != "" {
+		if t := field.Tag.Get(tonic.ExplodeTag); t != "" {
+			if explode, err := strconv.ParseBool(t); err == nil { // ignore invalid values
+				p.Explode = explode
+			}
+		}
+	}
+	return p, in, nil
+}
+
+// parameterIn returns the location of a parameter
+// in the specification from its location tag.
+func (g *Generator) parameterIn(tag string) string {
+	switch tag {
+	case g.config.PathLocationTag:
+		return "path"
+	case g.config.QueryLocationTag:
+		return "query"
+	case g.config.HeaderLocationTag:
+		return "header"
+	case g.config.CookieLocationTag:
+		return "cookie"
+	case g.config.FormLocationTag:
+		return "form"
+	}
+	return tag
+}
+
+// setIntegerStringSchema describes the integer struct field
+// as a string of digits, for the integers serialized as
+// strings to preserve their precision in JavaScript. The
+// format of the integer is kept.
+func (g *Generator) setIntegerStringSchema(schema *Schema, sf reflect.StructField, fname string, parent reflect.Type) {
+	t := sf.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		schema.Pattern = "^-?[0-9]+$"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema.Pattern = "^[0-9]+$"
+	default:
+		g.error(&FieldError{
+			Message:  fmt.Sprintf("format %s can only be applied to an integer field", int64StringFormat),
+			Name:     fname,
+			Type:     sf.Type,
+			TypeName: g.typeName(sf.Type),
+			Parent:   parent,
+		})
+		return
+	}
+	schema.Type = "string"
+}
+
+// isObjectType returns whether the type t, or the type
+// it points to, is described by an object schema.
+func isObjectType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Map
 }
 
 // paramLocation parses the tags of the struct field to extract
@@ -789,7 +1824,13 @@ func (g *Generator) paramLocation(f reflect.StructField, parameterLocations []st
 // newSchemaFromStructField returns a new Schema builded
 // from the field's type and its tags.
 func (g *Generator) newSchemaFromStructField(sf reflect.StructField, required bool, fname string, parent reflect.Type, mediaType string) *SchemaOrRef {
-	sor := g.newSchemaFromType(sf.Type, mediaType)
+	var sor *SchemaOrRef
+	if s, ok := g.fieldOverrides[parent][sf.Name]; ok {
+		cpy := *s
+		sor = &SchemaOrRef{Schema: &cpy}
+	} else {
+		sor = g.newSchemaFromType(sf.Type, mediaType)
+	}
 	if sor == nil {
 		return nil
 	}
@@ -830,15 +1871,39 @@ func (g *Generator) newSchemaFromStructField(sf reflect.StructField, required bo
 	// Enum.
 	// Must be applied to underlying items schema if the
 	// parameter is an array, instead of the parameter schema.
-	enum := g.enumFromStructField(sf, fname, parent)
-
-	if schema.Type == "array" && schema.Items != nil {
-		itemsSchema := g.resolveSchema(schema.Items)
-		if itemsSchema != nil {
-			itemsSchema.Enum = enum
+	enum, varNames := g.enumFromStructField(sf, fname, parent)
+
+	// The descriptions of the enum values are
+	// positionally aligned with the values.
+	var enumDescs []string
+	if t, ok := sf.Tag.Lookup(enumDescriptionsTag); ok {
+		enumDescs = strings.Split(t, ",")
+		if len(enumDescs) != len(enum) {
+			g.error(&FieldError{
+				Message:  fmt.Sprintf("field has %d enum descriptions for %d enum values", len(enumDescs), len(enum)),
+				Name:     fname,
+				Type:     sf.Type,
+				TypeName: g.typeName(sf.Type),
+				Parent:   parent,
+			})
+			enumDescs = nil
+		}
+	}
+	// A field without enum values keeps the
+	// values of a custom schema, if any.
+	if enum != nil {
+		if schema.Type == "array" && schema.Items != nil {
+			itemsSchema := g.resolveSchema(schema.Items)
+			if itemsSchema != nil {
+				itemsSchema.Enum = enum
+				itemsSchema.XEnumVarNames = varNames
+				itemsSchema.XEnumDescs = enumDescs
+			}
+		} else {
+			schema.Enum = enum
+			schema.XEnumVarNames = varNames
+			schema.XEnumDescs = enumDescs
 		}
-	} else {
-		schema.Enum = enum
 	}
 	// Field description.
 	if desc, ok := sf.Tag.Lookup(descriptionTag); ok {
@@ -848,14 +1913,83 @@ func (g *Generator) newSchemaFromStructField(sf reflect.StructField, required bo
 	// Consider invalid values as false.
 	schema.Deprecated, _ = strconv.ParseBool(sf.Tag.Get(deprecatedTag))
 
+	// Read-only and write-only.
+	// Consider invalid values as false. A field
+	// cannot be both read-only and write-only.
+	readOnly, _ := strconv.ParseBool(sf.Tag.Get(readOnlyTag))
+	writeOnly, _ := strconv.ParseBool(sf.Tag.Get(writeOnlyTag))
+	if readOnly && writeOnly {
+		g.error(&FieldError{
+			Message:  "field cannot be both read-only and write-only",
+			Name:     fname,
+			Type:     sf.Type,
+			TypeName: g.typeName(sf.Type),
+			Parent:   parent,
+		})
+	} else {
+		schema.ReadOnly = readOnly
+		schema.WriteOnly = writeOnly
+	}
+
 	// Update schema fields related to the JSON Validation
 	// spec based on the content of the validator tag.
 	schema = g.updateSchemaValidation(schema, sf)
 
+	// Pattern.
+	// Only strings can be validated against a
+	// regular expression.
+	if p, ok := sf.Tag.Lookup(patternTag); ok {
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if isString(ft) {
+			schema.Pattern = p
+		} else {
+			g.error(&FieldError{
+				Message:  fmt.Sprintf("pattern %s cannot be applied to a field of non-string type", p),
+				Name:     fname,
+				Type:     sf.Type,
+				TypeName: g.typeName(sf.Type),
+				Parent:   parent,
+			})
+		}
+	}
+
+	// Multiple of.
+	// Only numbers can be divided by a positive number.
+	if m, ok := sf.Tag.Lookup(multipleOfTag); ok {
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		n, err := strconv.ParseFloat(m, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) || !setSchemaMultipleOf(schema, n, ft) {
+			g.error(&FieldError{
+				Message:  fmt.Sprintf("multipleOf %s cannot be applied to the field, a positive number and a numeric field are required", m),
+				Name:     fname,
+				Type:     sf.Type,
+				TypeName: g.typeName(sf.Type),
+				Parent:   parent,
+			})
+		}
+	}
+
 	// Allow overidding schema properties that were
 	// auto inferred manually via tags.
 	if t, ok := sf.Tag.Lookup(formatTag); ok {
-		schema.Format = t
+		if t == int64StringFormat {
+			g.setIntegerStringSchema(schema, sf, fname, parent)
+		} else {
+			schema.Format = t
+		}
+	}
+	// Passwords are input-only, unless the field
+	// has an explicit read-only or write-only tag.
+	if schema.Type == TypePassword.Type() && schema.Format == TypePassword.Format() && !schema.ReadOnly {
+		if _, ok := sf.Tag.Lookup(writeOnlyTag); !ok {
+			schema.WriteOnly = true
+		}
 	}
 
 	// Set example value from tag to schema
@@ -876,33 +2010,43 @@ func (g *Generator) newSchemaFromStructField(sf reflect.StructField, required bo
 	return sor
 }
 
-func (g *Generator) enumFromStructField(sf reflect.StructField, fname string, parent reflect.Type) []interface{} {
+// enumFromStructField returns the enum values of the struct
+// field, read from its enum tag or registered for its type,
+// and the names of the registered values.
+func (g *Generator) enumFromStructField(sf reflect.StructField, fname string, parent reflect.Type) ([]interface{}, []string) {
 	var enum []interface{}
 
-	etag := sf.Tag.Get(g.config.EnumTag)
-	if etag != "" {
-		values := strings.Split(etag, ",")
-		sftype := sf.Type
+	sftype := sf.Type
 
-		// Use underlying element type if it's an array/slice/pointer
-		for sftype.Kind() == reflect.Ptr || sftype.Kind() == reflect.Slice || sftype.Kind() == reflect.Array {
-			sftype = sftype.Elem()
+	// Use underlying element type if it's an array/slice/pointer
+	for sftype.Kind() == reflect.Ptr || sftype.Kind() == reflect.Slice || sftype.Kind() == reflect.Array {
+		sftype = sftype.Elem()
+	}
+	etag := sf.Tag.Get(g.config.EnumTag)
+	if etag == "" {
+		// Use the values registered for the
+		// type of the field, if any.
+		var names []string
+		for _, v := range g.enums[sftype] {
+			enum = append(enum, v.Value)
+			names = append(names, v.Name)
 		}
-		for _, val := range values {
-			if v, err := stringToType(val, sftype); err != nil {
-				g.error(&FieldError{
-					Message:  fmt.Sprintf("enum value %s cannot be converted to field type: %s", val, err),
-					Name:     fname,
-					Type:     sf.Type,
-					TypeName: g.typeName(sf.Type),
-					Parent:   parent,
-				})
-			} else {
-				enum = append(enum, v)
-			}
+		return enum, names
+	}
+	for _, val := range strings.Split(etag, ",") {
+		if v, err := stringToType(val, sftype); err != nil {
+			g.error(&FieldError{
+				Message:  fmt.Sprintf("enum value %s cannot be converted to field type: %s", val, err),
+				Name:     fname,
+				Type:     sf.Type,
+				TypeName: g.typeName(sf.Type),
+				Parent:   parent,
+			})
+		} else {
+			enum = append(enum, v)
 		}
 	}
-	return enum
+	return enum, nil
 }
 
 // newSchemaFromType creates a new OpenAPI schema from
@@ -911,6 +2055,7 @@ func (g *Generator) newSchemaFromType(t reflect.Type, mediaType string) *SchemaO
 	if t == nil {
 		return nil
 	}
+	g.finalized = false
 	var nullable bool
 
 	// Dereference pointer.
@@ -922,6 +2067,19 @@ func (g *Generator) newSchemaFromType(t reflect.Type, mediaType string) *SchemaO
 		if ok {
 			nullable = i.Nullable()
 		}
+	} else if _, ok := sqlNullTypes[t]; ok {
+		nullable = true
+	}
+	if schema := g.overrideSchema(t); schema != nil {
+		schema.Nullable = schema.Nullable || nullable
+		return &SchemaOrRef{Schema: schema}
+	}
+	if sor := g.newSchemaFromInterface(t, mediaType); sor != nil {
+		return sor
+	}
+	if sor := g.newSchemaFromVariants(t, mediaType); sor != nil {
+		sor.Schema.Nullable = nullable
+		return sor
 	}
 	dt := g.datatype(t)
 
@@ -935,7 +2093,13 @@ func (g *Generator) newSchemaFromType(t reflect.Type, mediaType string) *SchemaO
 	if dt == TypeComplex {
 		switch t.Kind() {
 		case reflect.Slice, reflect.Array, reflect.Map:
-			return g.buildSchemaRecursive(t, mediaType)
+			sor := g.buildSchemaRecursive(t, mediaType)
+			// The inlined schema of a pointer is nullable,
+			// unlike a reference to a component schema.
+			if sor != nil && sor.Schema != nil && nullable {
+				sor.Schema.Nullable = true
+			}
+			return sor
 		case reflect.Struct:
 			return g.newSchemaFromStruct(t, mediaType)
 		}
@@ -953,23 +2117,39 @@ func (g *Generator) newSchemaFromType(t reflect.Type, mediaType string) *SchemaO
 		Format:   dt.Format(),
 		Nullable: nullable,
 	}
+	// The custom types serialized as arrays
+	// may describe the schema of their items.
+	if adt, ok := dt.(ArrayDataType); ok {
+		if items := adt.Items(); items != nil {
+			schema.Items = &SchemaOrRef{Schema: items}
+		}
+	}
 	return &SchemaOrRef{Schema: schema}
 }
 
 // buildSchemaRecursive recursively decomposes the complex
 // type t into subsequent schemas.
 func (g *Generator) buildSchemaRecursive(t reflect.Type, mediaType string) *SchemaOrRef {
+	if schema := g.overrideSchema(t); schema != nil {
+		return &SchemaOrRef{Schema: schema}
+	}
+	if sor := g.newSchemaFromVariants(t, mediaType); sor != nil {
+		return sor
+	}
 	schema := &Schema{}
 	// Switch over Golang types.
 	switch t {
 	case tofTime:
 		schema.Type, schema.Format = TypeDateTime.Type(), TypeDateTime.Format()
 	case tofDuration:
-		schema.Type, schema.Format = TypeDuration.Type(), TypeDuration.Format()
+		dt := g.datatype(t)
+		schema.Type, schema.Format = dt.Type(), dt.Format()
 	case tofByteSlice:
 		schema.Type, schema.Format = TypeByte.Type(), TypeByte.Format()
 	case tofNetIP:
 		schema.Type, schema.Format = TypeIP.Type(), TypeIP.Format()
+	case tofNetIPNet:
+		schema.Type, schema.Format = TypeCIDR.Type(), TypeCIDR.Format()
 	case tofNetURL:
 		schema.Type, schema.Format = TypeURL.Type(), TypeURL.Format()
 	case tofEmptyInterface:
@@ -977,9 +2157,32 @@ func (g *Generator) buildSchemaRecursive(t reflect.Type, mediaType string) *Sche
 	case tofFileHeader:
 		schema.Type, schema.Format = TypeFile.Type(), TypeFile.Format()
 	default:
+		// The imported types, such as UUIDs, may
+		// be declared as arrays of bytes.
+		if dt := isImportedType(t); dt != nil {
+			schema.Type, schema.Format = dt.Type(), dt.Format()
+			break
+		}
+		if isTextMarshaler(t) {
+			dt := g.datatype(t)
+			schema.Type, schema.Format = dt.Type(), dt.Format()
+			break
+		}
+		if _, ok := sqlNullTypes[t]; ok {
+			dt := g.datatype(t)
+			schema.Type, schema.Format = dt.Type(), dt.Format()
+			schema.Nullable = true
+			break
+		}
 		switch t.Kind() {
 		case reflect.Ptr:
 			return g.buildSchemaRecursive(t.Elem(), mediaType)
+		case reflect.Interface:
+			if sor := g.newSchemaFromInterface(t, mediaType); sor != nil {
+				return sor
+			}
+			dt := g.datatype(t)
+			schema.Type, schema.Format = dt.Type(), dt.Format()
 		case reflect.Struct:
 			return g.newSchemaFromStruct(t, mediaType)
 		case reflect.Map:
@@ -996,7 +2199,13 @@ func (g *Generator) buildSchemaRecursive(t reflect.Type, mediaType string) *Sche
 				})
 				return nil
 			}
-			schema.AdditionalProperties = g.buildSchemaRecursive(t.Elem(), mediaType)
+			// The values of a map of empty interfaces
+			// can be of any type.
+			if t.Elem() == tofEmptyInterface {
+				schema.AdditionalProperties = &SchemaOrRef{}
+			} else {
+				schema.AdditionalProperties = g.buildSchemaRecursive(t.Elem(), mediaType)
+			}
 		case reflect.Slice, reflect.Array:
 			// Slice/Array types are considered as a type
 			// "array" and should declare underlying items
@@ -1018,6 +2227,107 @@ func (g *Generator) buildSchemaRecursive(t reflect.Type, mediaType string) *Sche
 	return &SchemaOrRef{Schema: schema}
 }
 
+// newSchemaFromInterface returns an OpenAPI schema that
+// describes the interface type t as one of the schemas of
+// its registered implementations, or nil if t isn't an
+// interface or has no registered implementations.
+func (g *Generator) newSchemaFromInterface(t reflect.Type, mediaType string) *SchemaOrRef {
+	if t.Kind() != reflect.Interface {
+		return nil
+	}
+	ii, ok := g.interfaces[t]
+	if !ok {
+		return nil
+	}
+	schema := &Schema{}
+	refs := make(map[reflect.Type]string, len(ii.types))
+	for _, impl := range ii.types {
+		if sor := g.newSchemaFromType(impl, mediaType); sor != nil {
+			schema.OneOf = append(schema.OneOf, sor)
+			if sor.Reference != nil {
+				refs[impl] = sor.Ref
+			}
+		}
+	}
+	if ii.discriminator != "" {
+		schema.Discriminator = &Discriminator{
+			PropertyName: ii.discriminator,
+		}
+		for value, impl := range ii.mapping {
+			ref, ok := refs[impl]
+			if !ok {
+				g.error(&TypeError{
+					Message: fmt.Sprintf("discriminator value %s is mapped to a type without a component schema", value),
+					Type:    impl,
+				})
+				continue
+			}
+			if schema.Discriminator.Mapping == nil {
+				schema.Discriminator.Mapping = make(map[string]string)
+			}
+			schema.Discriminator.Mapping[value] = ref
+		}
+	}
+	return &SchemaOrRef{Schema: schema}
+}
+
+// schemaName returns the name of the
+// component schema of the struct type t.
+func (g *Generator) schemaName(t reflect.Type) string {
+	name := g.schemaTitle(t)
+	if name == "" {
+		return ""
+	}
+	return g.componentName(name)
+}
+
+// schemaTitle returns the title of the component
+// schema of the struct type t, which is its name
+// without the configured prefix and suffix.
+func (g *Generator) schemaTitle(t reflect.Type) string {
+	return refRe.ReplaceAllString(strings.Replace(g.typeName(t), "[]", "Array", 1), "")
+}
+
+// componentName returns the name of a component
+// schema with the configured prefix and suffix.
+func (g *Generator) componentName(name string) string {
+	return g.schemaPrefix + name + g.schemaSuffix
+}
+
+// newSchemaFromVariants returns an OpenAPI schema that
+// describes the type t as any of the schemas of the values
+// returned by its Variants method, or nil if t doesn't
+// implement the Variants interface.
+func (g *Generator) newSchemaFromVariants(t reflect.Type, mediaType string) *SchemaOrRef {
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
+		return nil
+	}
+	v, ok := reflect.New(t).Interface().(Variants)
+	if !ok {
+		return nil
+	}
+	schema := &Schema{}
+	for _, variant := range v.Variants() {
+		vt := reflect.TypeOf(variant)
+		if vt == nil || vt == t || vt == reflect.PtrTo(t) {
+			// Skip the nil values, and the type itself
+			// to avoid a recursive loop.
+			continue
+		}
+		if sor := g.newSchemaFromType(vt, mediaType); sor != nil {
+			schema.AnyOf = append(schema.AnyOf, sor)
+		}
+	}
+	if len(schema.AnyOf) == 0 {
+		g.error(&TypeError{
+			Message: "type implements Variants but has no variants",
+			Type:    t,
+		})
+		return nil
+	}
+	return &SchemaOrRef{Schema: schema}
+}
+
 // structSchema returns an OpenAPI schema that describe
 // the Go struct represented by the type t.
 func (g *Generator) newSchemaFromStruct(t reflect.Type, mediaType string) *SchemaOrRef {
@@ -1025,8 +2335,7 @@ func (g *Generator) newSchemaFromStruct(t reflect.Type, mediaType string) *Schem
 		return nil
 	}
 
-	typeName := g.typeName(t)
-	name := refRe.ReplaceAllString(strings.Replace(typeName, "[]", "Array", 1), "")
+	name := g.schemaName(t)
 
 	// If the type of the field has already been registered,
 	// skip the schema generation to avoid a recursive loop.
@@ -1046,9 +2355,32 @@ func (g *Generator) newSchemaFromStruct(t reflect.Type, mediaType string) *Schem
 	// struct are all considered unique.
 	if name != "" {
 		g.schemaTypes[t] = struct{}{}
+		schema.Title = g.schemaTitle(t)
+	}
+	// The schema of a named struct is the root of its
+	// component, and so is the schema of an anonymous
+	// struct nested deeper than the maximum depth.
+	depth := g.schemaDepth
+	hoist := name == "" && g.maxDepth > 0 && depth > g.maxDepth
+	if name != "" || hoist {
+		g.schemaDepth = 1
+	} else {
+		g.schemaDepth++
 	}
 	schema = g.flattenStructSchema(t, t, schema, mediaType)
+	g.schemaDepth = depth
 
+	// Use the description of the type, if it
+	// implements the SchemaDescriber interface.
+	if sd, ok := reflect.New(t).Interface().(SchemaDescriber); ok {
+		schema.Description = sd.SchemaDescription()
+	}
+	if ext, ok := g.schemaExts[t]; ok {
+		schema.Extensions = make(map[string]interface{}, len(ext))
+		for k, v := range ext {
+			schema.Extensions[k] = v
+		}
+	}
 	sor := &SchemaOrRef{Schema: schema}
 
 	// Register the schema within the speccomponents and return a
@@ -1061,10 +2393,32 @@ func (g *Generator) newSchemaFromStruct(t reflect.Type, mediaType string) *Schem
 			Ref: componentsSchemaPath + name,
 		}}
 	}
+	if hoist {
+		return g.hoistSchema(sor)
+	}
 	// Return an inlined schema for types with no name.
 	return sor
 }
 
+// hoistSchema moves the inlined schema of an anonymous
+// struct into the components of the specification, with
+// the name of a deduplicated schema, and returns a reference.
+func (g *Generator) hoistSchema(sor *SchemaOrRef) *SchemaOrRef {
+	fp, ok := schemaFingerprint(sor)
+	if !ok {
+		return sor
+	}
+	name, ok := g.dedupedSchemas[fp]
+	if !ok {
+		name = g.dedupedSchemaName(fp)
+		g.dedupedSchemas[fp] = name
+		g.api.Components.Schemas[name] = sor
+	}
+	return &SchemaOrRef{Reference: &Reference{
+		Ref: componentsSchemaPath + name,
+	}}
+}
+
 // flattenStructSchema recursively flatten the embedded
 // fields of the struct type t to the given schema.
 func (g *Generator) flattenStructSchema(t, parent reflect.Type, schema *Schema, mediaType string) *Schema {
@@ -1077,7 +2431,7 @@ func (g *Generator) flattenStructSchema(t, parent reflect.Type, schema *Schema,
 			ft = ft.Elem()
 		}
 		isUnexported := f.PkgPath != ""
-		mediaTag := mediaTags[tonic.MediaType()]
+		mediaTag := mediaTagName(g.DefaultContentType())
 		_, hasTag := f.Tag.Lookup(mediaTag)
 
 		if f.Anonymous && !hasTag {
@@ -1108,8 +2462,11 @@ func (g *Generator) flattenStructSchema(t, parent reflect.Type, schema *Schema,
 			// Ignore unexported non-embedded fields.
 			continue
 		}
-
-		fname := fieldNameFromTag(f, mediaTags[mediaType])
+		if f.Tag.Get(bindingTag) == "-" {
+			// Ignore fields excluded from the binding.
+			continue
+		}
+		fname := fieldNameFromTag(f, mediaTagName(mediaType))
 		if fname == "" {
 			// Field has no name, skip it.
 			continue
@@ -1117,13 +2474,18 @@ func (g *Generator) flattenStructSchema(t, parent reflect.Type, schema *Schema,
 		var required bool
 		// The required property of a field is not part of its
 		// own schema but specified in the parent schema.
-		if fname != "" && g.isStructFieldRequired(f) {
+		if fname != "" && g.isSchemaPropertyRequired(f, mediaTagName(mediaType)) {
 			required = true
 			schema.Required = append(schema.Required, fname)
 			sort.Strings(schema.Required)
 		}
+		schema.RequiredConditions = append(schema.RequiredConditions, g.requiredConditions(f, t, fname, mediaTagName(mediaType))...)
+
 		sfs := g.newSchemaFromStructField(f, required, fname, t, mediaType)
 		if sfs != nil {
+			if _, ok := schema.Properties[fname]; !ok {
+				schema.propertyOrder = append(schema.propertyOrder, fname)
+			}
 			schema.Properties[fname] = sfs
 		}
 	}
@@ -1131,17 +2493,20 @@ func (g *Generator) flattenStructSchema(t, parent reflect.Type, schema *Schema,
 }
 
 // isStructFieldRequired returns whether a struct field
-// is required. The information is read from the field
-// tag 'binding'.
+// is required. The information is read from the tag of
+// the validator, or from the 'binding' tag of Gin.
 func (g *Generator) isStructFieldRequired(sf reflect.StructField) bool {
-	if t, ok := sf.Tag.Lookup(g.config.ValidatorTag); ok {
-		options := strings.Split(t, ",")
-		for _, o := range options {
+	for _, tag := range []string{g.config.ValidatorTag, bindingTag} {
+		t, ok := sf.Tag.Lookup(tag)
+		if !ok {
+			continue
+		}
+		for _, o := range strings.Split(t, ",") {
 			// As soon as we see a 'dive' or 'keys'
 			// options, the following options won't
 			// apply to the given field.
 			if o == "dive" || o == "keys" {
-				return false
+				break
 			}
 			if o == "required" {
 				return true
@@ -1151,6 +2516,77 @@ func (g *Generator) isStructFieldRequired(sf reflect.StructField) bool {
 	return false
 }
 
+// requiredConditions returns the conditions under which a
+// struct field is required, read from the required_if,
+// required_with and required_without options of the tag
+// of the validator. The names of the other fields of the struct
+// type t are converted to the names of their properties.
+func (g *Generator) requiredConditions(sf reflect.StructField, t reflect.Type, fname, tagName string) []*RequiredCondition {
+	ts, ok := sf.Tag.Lookup(g.config.ValidatorTag)
+	if !ok || fname == "" {
+		return nil
+	}
+	var conds []*RequiredCondition
+
+	for _, o := range strings.Split(ts, ",") {
+		// The options that follow 'dive' or 'keys'
+		// apply to the elements of the field.
+		if o == "dive" || o == "keys" {
+			break
+		}
+		kv := strings.SplitN(o, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "required_if", "required_with", "required_without":
+		default:
+			continue
+		}
+		params := strings.Fields(kv[1])
+		if len(params) == 0 || kv[0] == "required_if" && len(params)%2 != 0 {
+			g.error(&FieldError{
+				Message:  fmt.Sprintf("invalid %s validator parameters: %s", kv[0], kv[1]),
+				Name:     sf.Name,
+				TypeName: g.typeName(t),
+				Type:     t,
+			})
+			continue
+		}
+		c := &RequiredCondition{
+			Property:  fname,
+			Validator: kv[0],
+		}
+		for i, p := range params {
+			// The parameters of required_if are
+			// pairs of field names and values.
+			if kv[0] == "required_if" && i%2 == 1 {
+				c.Values = append(c.Values, p)
+				continue
+			}
+			if f, ok := t.FieldByName(p); ok {
+				if name := fieldNameFromTag(f, tagName); name != "" {
+					p = name
+				}
+			}
+			c.Fields = append(c.Fields, p)
+		}
+		conds = append(conds, c)
+	}
+	return conds
+}
+
+// isSchemaPropertyRequired returns whether a struct field
+// is listed in the required properties of the schema of its
+// parent. A pointer field with the omitempty option of the
+// given tag is omitted when nil, and is never required.
+func (g *Generator) isSchemaPropertyRequired(sf reflect.StructField, tagName string) bool {
+	if sf.Type.Kind() == reflect.Ptr && hasOmitEmpty(sf, tagName) {
+		return false
+	}
+	return g.isStructFieldRequired(sf)
+}
+
 // resolveSchema returns either the inlined schema
 // in s or the one referenced in the API components.
 func (g *Generator) resolveSchema(s *SchemaOrRef) *Schema {
@@ -1223,12 +2659,60 @@ func (g *Generator) typeName(t reflect.Type) string {
 	}
 	typ := name[sp+1:]
 
+	// The name of an instantiated generic type
+	// contains the names of its type arguments.
+	if strings.Contains(typ, "[") {
+		typ = g.genericTypeName(typ)
+	}
 	if !g.fullNames {
 		return strings.Title(typ)
 	}
 	return strings.Title(pkg) + strings.Title(typ)
 }
 
+// genericTypeName returns the name of an instantiated
+// generic type, such as Result[[]pkg.User], as the
+// concatenation of the name of the generic type and of
+// the names of its type arguments, like ResultArrayUser.
+// The import paths of the packages are removed, and the
+// package names are kept if full names are used.
+func (g *Generator) genericTypeName(name string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(name); {
+		switch {
+		case strings.HasPrefix(name[i:], "[]"):
+			sb.WriteString("Array")
+			i += len("[]")
+		case strings.HasPrefix(name[i:], "map["):
+			sb.WriteString("Map")
+			i += len("map[")
+		case strings.ContainsRune("[]*, ", rune(name[i])):
+			i++
+		default:
+			j := i
+			for j < len(name) && !strings.ContainsRune("[]*, ", rune(name[j])) {
+				j++
+			}
+			ident := name[i:j]
+			i = j
+
+			// A qualified identifier is composed of the
+			// import path of its package and its name.
+			if dot := strings.LastIndex(ident, "."); dot != -1 {
+				pkg := ident[:dot]
+				pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+				if g.fullNames && pkg != "main" {
+					sb.WriteString(strings.Title(pkg))
+				}
+				ident = ident[dot+1:]
+			}
+			sb.WriteString(strings.Title(ident))
+		}
+	}
+	return sb.String()
+}
+
 // updateSchemaValidation fills the fields of the schema
 // related to the JSON Schema Validation RFC based on the
 // content of the validator tag.
@@ -1248,10 +2732,6 @@ func (g *Generator) updateSchemaValidation(schema *Schema, sf reflect.StructFiel
 		if t == "dive" || t == "keys" {
 			break
 		}
-		if t == "email" {
-			schema.Format = "email"
-			break
-		}
 		// Tags can be joined together with an OR operator.
 		parts := strings.Split(t, "|")
 
@@ -1265,26 +2745,58 @@ func (g *Generator) updateSchemaValidation(schema *Schema, sf reflect.StructFiel
 				k = p[:sepIdx]
 				v = p[sepIdx+1:]
 			}
-			// Handle validators with value.
+			// Handle validators.
 			switch k {
-			case "len", "max", "min", "eq", "gt", "gte", "lt", "lte":
-				n, err := strconv.Atoi(v)
-				if err != nil {
+			case "unique":
+				if isArray(ft) {
+					schema.UniqueItems = true
+				}
+			case "alpha", "alphanum", "numeric", "hexadecimal":
+				// A pattern cannot describe an alternative
+				// between several validators.
+				if isString(ft) && len(parts) == 1 {
+					schema.Pattern = validatorPatterns[k]
+				}
+			case "datetime":
+				if isString(ft) && len(parts) == 1 && schema.Format == "" {
+					schema.Format = datetimeFormats[v]
+				}
+			case "len", "max", "min", "eq", "gt", "gte", "lt", "lte", "multiple_of":
+				n, err := strconv.ParseFloat(v, 64)
+				if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+					// The length of a field must be
+					// known to be described.
+					if k == "len" {
+						g.error(&FieldError{
+							Message:  fmt.Sprintf("invalid len validator value %q", v),
+							Name:     sf.Name,
+							Type:     sf.Type,
+							TypeName: g.typeName(sf.Type),
+						})
+					}
 					continue
 				}
 				switch k {
 				case "len":
 					setSchemaLen(schema, n, ft)
 				case "max", "lte":
-					setSchemaMax(schema, n, ft)
+					setSchemaMax(schema, n, false, ft)
 				case "min", "gte":
-					setSchemaMin(schema, n, ft)
+					setSchemaMin(schema, n, false, ft)
 				case "lt":
-					setSchemaMax(schema, n-1, ft)
+					setSchemaMax(schema, n, true, ft)
 				case "gt":
-					setSchemaMin(schema, n+1, ft)
+					setSchemaMin(schema, n, true, ft)
 				case "eq":
 					setSchemaEq(schema, n, ft)
+				case "multiple_of":
+					setSchemaMultipleOf(schema, n, ft)
+				}
+			default:
+				// The format of the type of the
+				// field has precedence.
+				if f, ok := validatorFormats[k]; ok && isString(ft) && len(parts) == 1 && schema.Format == "" {
+					schema.Format = f
 				}
 			}
 		}
@@ -1296,7 +2808,7 @@ func (g *Generator) error(err error) {
 	g.errors = append(g.errors, err)
 }
 
-// fieldTagName returns the name of a struct field
+// fieldNameFromTag returns the name of a struct field
 // extracted from a serialization tag using its name.
 func fieldNameFromTag(sf reflect.StructField, tagName string) string {
 	v, ok := sf.Tag.Lookup(tagName)
@@ -1318,6 +2830,21 @@ func fieldNameFromTag(sf reflect.StructField, tagName string) string {
 	return name
 }
 
+// hasOmitEmpty returns whether the tag of the struct
+// field with the given name has the omitempty option.
+func hasOmitEmpty(sf reflect.StructField, tagName string) bool {
+	v, ok := sf.Tag.Lookup(tagName)
+	if !ok {
+		return false
+	}
+	for _, o := range strings.Split(v, ",")[1:] {
+		if strings.TrimSpace(o) == "omitempty" {
+			return true
+		}
+	}
+	return false
+}
+
 // / parseExampleValue is used to transform the string representation of the example value to the correct type.
 func parseExampleValue(t reflect.Type, value string) (interface{}, error) {
 	// If the type implements Exampler use the ParseExample method to create the example
@@ -1,18 +1,26 @@
 package openapi
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/Pallinder/go-randomdata"
 	"github.com/ccfish86/gadgeto/tonic"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
 )
 
 var genConfig = &SpecGenConfig{
@@ -20,6 +28,7 @@ var genConfig = &SpecGenConfig{
 	PathLocationTag:   tonic.PathTag,
 	QueryLocationTag:  tonic.QueryTag,
 	HeaderLocationTag: tonic.HeaderTag,
+	CookieLocationTag: "cookie",
 	EnumTag:           tonic.EnumTag,
 	DefaultTag:        tonic.DefaultTag,
 }
@@ -127,6 +136,32 @@ func TestAddTag(t *testing.T) {
 	assert.Equal(t, "A", tag.Name)
 }
 
+// TestSetTagOrder tests that the tags with a custom
+// order come first, followed by the others sorted
+// by name.
+func TestSetTagOrder(t *testing.T) {
+	g := gen(t)
+
+	for _, name := range []string{"Users", "Billing", "Admin", "Auth"} {
+		g.AddTag(name, "")
+	}
+	g.SetTagOrder("Auth", "Users", "Admin", "Unknown")
+
+	// Tags added afterward follow the same order.
+	g.AddTag("Accounts", "")
+
+	var names []string
+	for _, tag := range g.API().Tags {
+		names = append(names, tag.Name)
+	}
+	assert.Equal(t, []string{"Auth", "Users", "Admin", "Accounts", "Billing"}, names)
+
+	b, err := json.Marshal(g.API().Tags)
+	if assert.Nil(t, err) {
+		assert.Equal(t, `[{"name":"Auth"},{"name":"Users"},{"name":"Admin"},{"name":"Accounts"},{"name":"Billing"}]`, string(b))
+	}
+}
+
 // TestSchemaFromPrimitiveType tests that a schema
 // can be created given a primitive input type.
 func TestSchemaFromPrimitiveType(t *testing.T) {
@@ -190,6 +225,42 @@ func TestSchemaFromMapWithUnsupportedKeys(t *testing.T) {
 	assert.NotEmpty(t, g.Errors()[0].Error())
 }
 
+// TestSchemaFromMap tests that the schema of a map
+// describes the values with its additional properties.
+func TestSchemaFromMap(t *testing.T) {
+	tests := []struct {
+		typ      reflect.Type
+		expected string
+	}{
+		{rt(map[string]*Y{}), `{"type":"object","additionalProperties":{"$ref":"#/components/schemas/Y"}}`},
+		{rt(map[string]string{}), `{"type":"object","additionalProperties":{"type":"string"}}`},
+		{rt(map[string]interface{}{}), `{"type":"object","additionalProperties":true}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ.String(), func(t *testing.T) {
+			g := gen(t)
+
+			sor := g.newSchemaFromType(tt.typ, tonic.MediaType())
+			assert.NotNil(t, sor)
+			assert.Empty(t, g.Errors())
+
+			b, err := json.Marshal(sor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.JSONEq(t, tt.expected, string(b))
+		})
+	}
+	g := gen(t)
+
+	sor := g.newSchemaFromType(rt(map[string]interface{}{}), tonic.MediaType())
+	b, err := yaml.Marshal(sor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "type: object\nadditionalProperties: true\n", string(b))
+}
+
 // TestSchemaFromComplex tests that a schema
 // can be created from a complex type.
 func TestSchemaFromComplex(t *testing.T) {
@@ -247,6 +318,474 @@ func TestSchemaFromComplex(t *testing.T) {
 	}
 }
 
+// TestTyperNestedFields tests that the name returned by
+// the Typer interface is used by the references to the
+// type in the fields, slices, arrays and maps of a struct.
+func TestTyperNestedFields(t *testing.T) {
+	type T struct {
+		F  *X
+		G  X
+		Xs []*X
+		A  [3]*X
+		M  map[string]X
+	}
+	g := gen(t)
+
+	sor := g.newSchemaFromType(rt(T{}), tonic.MediaType())
+	schema := g.resolveSchema(sor)
+	if !assert.NotNil(t, schema) {
+		return
+	}
+	ref := componentsSchemaPath + "XXX"
+
+	assert.Equal(t, ref, schema.Properties["F"].Ref)
+	assert.Equal(t, ref, schema.Properties["G"].Ref)
+	assert.Equal(t, ref, schema.Properties["Xs"].Items.Ref)
+	assert.Equal(t, ref, schema.Properties["A"].Items.Ref)
+	assert.Equal(t, ref, schema.Properties["M"].AdditionalProperties.Ref)
+
+	b, err := json.Marshal(schema.Properties["Xs"])
+	if assert.Nil(t, err) {
+		assert.Equal(t, `{"type":"array","items":{"$ref":"#/components/schemas/XXX"}}`, string(b))
+	}
+	assert.Contains(t, g.API().Components.Schemas, "XXX")
+	assert.NotContains(t, g.API().Components.Schemas, "X")
+}
+
+// TestRequiredNullableFields tests that the required
+// pointer fields are listed in the required properties
+// of the schema and stay nullable.
+func TestRequiredNullableFields(t *testing.T) {
+	type T struct {
+		I *int            `validate:"required"`
+		S *[]string       `validate:"required"`
+		M *map[string]int `validate:"required"`
+		N *int
+	}
+	g := gen(t)
+
+	sor := g.newSchemaFromType(rt(T{}), tonic.MediaType())
+	schema := g.resolveSchema(sor)
+	if !assert.NotNil(t, schema) {
+		return
+	}
+	assert.ElementsMatch(t, []string{"I", "S", "M"}, schema.Required)
+
+	for _, name := range []string{"I", "S", "M", "N"} {
+		if assert.NotNil(t, schema.Properties[name], name) {
+			assert.True(t, schema.Properties[name].Nullable, name)
+		}
+	}
+	assert.Empty(t, g.Errors())
+}
+
+// TestSchemaPropertiesOrder tests that the properties
+// of the schema of a struct are marshaled in the order
+// of the declaration of its fields.
+func TestSchemaPropertiesOrder(t *testing.T) {
+	type Embedded struct {
+		Beta string `json:"beta"`
+	}
+	type T struct {
+		Zeta  string `json:"zeta"`
+		Alpha int    `json:"alpha"`
+		Embedded
+		Mid struct {
+			Z bool `json:"zz"`
+			A bool `json:"aa"`
+		} `json:"mid"`
+		Omega *int `json:"omega"`
+	}
+	for _, version := range []string{"3.0.3", "3.1.0"} {
+		g := gen(t)
+		if err := g.SetOpenAPIVersion(version); err != nil {
+			t.Fatal(err)
+		}
+		sor := g.newSchemaFromType(rt(T{}), tonic.MediaType())
+		if !assert.NotNil(t, sor) {
+			return
+		}
+		// Mark the schemas with the version.
+		g.API()
+		schema := g.resolveSchema(sor)
+
+		b, err := json.Marshal(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, err := yaml.Marshal(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tc := range []struct {
+			out  string
+			keys []string
+		}{
+			{string(b), []string{`"zeta"`, `"alpha"`, `"beta"`, `"mid"`, `"zz"`, `"aa"`, `"omega"`}},
+			{string(y), []string{"zeta:", "alpha:", "beta:", "mid:", "zz:", "aa:", "omega:"}},
+		} {
+			prev := -1
+			for _, key := range tc.keys {
+				i := strings.Index(tc.out, key)
+				assert.Greater(t, i, prev, "%s %s: %s", version, key, tc.out)
+				prev = i
+			}
+		}
+	}
+}
+
+// TestSchemaFromComplexOpenAPI31 tests that the nullable
+// schemas are described with a type array when the
+// version of the specification is 3.1.
+func TestSchemaFromComplexOpenAPI31(t *testing.T) {
+	g := gen(t)
+
+	err := g.SetOpenAPIVersion("2.0")
+	assert.NotNil(t, err)
+
+	err = g.SetOpenAPIVersion("3.1.0")
+	assert.Nil(t, err)
+
+	sor := g.newSchemaFromType(rt(new(X)), tonic.MediaType())
+	assert.NotNil(t, sor)
+
+	api := g.API()
+	assert.Equal(t, "3.1.0", api.OpenAPI)
+
+	actual, err := json.Marshal(api.Components.Schemas["XXX"])
+	if err != nil {
+		t.Error(err)
+	}
+	// see testdata/X31.json.
+	expected, err := ioutil.ReadFile("../testdata/schemas/X31.json")
+	if err != nil {
+		t.Error(err)
+	}
+	m, err := diffJSON(actual, expected)
+	if err != nil {
+		t.Error(err)
+	}
+	if !m {
+		t.Error("expected json outputs to be equal")
+	}
+	// The YAML output must describe the same schema.
+	b, err := yaml.Marshal(api.Components.Schemas["XXX"])
+	if err != nil {
+		t.Error(err)
+	}
+	var y map[string]interface{}
+	if err := yaml.Unmarshal(b, &y); err != nil {
+		t.Error(err)
+	}
+	props := y["properties"].(map[interface{}]interface{})
+	ns := props["NS"].(map[interface{}]interface{})
+	assert.Equal(t, []interface{}{"string", "null"}, ns["type"])
+	assert.NotContains(t, ns, "nullable")
+
+	// Switching back to 3.0 restores the nullable keyword.
+	err = g.SetOpenAPIVersion("3.0.3")
+	assert.Nil(t, err)
+
+	actual, err = json.Marshal(g.API().Components.Schemas["XXX"])
+	if err != nil {
+		t.Error(err)
+	}
+	expected, err = ioutil.ReadFile("../testdata/schemas/X.json")
+	if err != nil {
+		t.Error(err)
+	}
+	m, err = diffJSON(actual, expected)
+	if err != nil {
+		t.Error(err)
+	}
+	if !m {
+		t.Error("expected json outputs to be equal")
+	}
+}
+
+// TestAPIConcurrentReads tests that the specification
+// can be retrieved and marshaled concurrently, like the
+// handlers of the routes do, without modifying it.
+func TestAPIConcurrentReads(t *testing.T) {
+	type T struct {
+		A *string `json:"a"`
+		B struct {
+			C *int `json:"c"`
+		} `json:"b"`
+		D struct {
+			C *int `json:"c"`
+		} `json:"d"`
+	}
+	g := gen(t)
+	g.SetDedupeSchemas(true)
+
+	err := g.SetOpenAPIVersion("3.1.0")
+	assert.Nil(t, err)
+
+	err = g.SetDefaultResponse("500", "Server error", nil)
+	assert.Nil(t, err)
+
+	_, err = g.AddOperation("/t", "POST", "T", tonic.MediaType(), tonic.MediaType(), rt(new(T)), rt(new(T)), &OperationInfo{
+		ID:         "CreateT",
+		StatusCode: 201,
+	})
+	assert.Nil(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := json.Marshal(g.API()); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			op, ok := g.Operation("POST", "/t")
+			if !ok {
+				t.Error("expected operation to exist")
+				return
+			}
+			r := httptest.NewRequest("POST", "/t", strings.NewReader(`{"a":"x","b":{"c":1}}`))
+			r.Header.Set("Content-Type", "application/json")
+			assert.Empty(t, g.ValidateRequest(op, r, nil))
+		}()
+	}
+	wg.Wait()
+
+	assert.Empty(t, g.Errors())
+	if assert.Len(t, g.dedupedSchemas, 1) {
+		for _, name := range g.dedupedSchemas {
+			assert.Contains(t, g.SchemaNames(), name)
+		}
+	}
+}
+
+type (
+	shape    interface{ Area() float64 }
+	Circle   struct{ Kind, Radius string }
+	Square   struct{ Kind, Side string }
+	Triangle struct{ Kind, Base, Height string }
+)
+
+func (Circle) Area() float64   { return 0 }
+func (*Square) Area() float64  { return 0 }
+func (Triangle) Area() float64 { return 0 }
+
+// TestDurationFormat tests that the schemas of the
+// durations follow the configured format.
+func TestDurationFormat(t *testing.T) {
+	type T struct {
+		Timeouts []time.Duration
+	}
+	for _, tc := range []struct {
+		format DurationFormat
+		typ    string
+		fmt    string
+	}{
+		{DurationString, "string", "duration"},
+		{DurationNanos, "integer", "int64"},
+		{DurationSeconds, "integer", "int64"},
+	} {
+		g := gen(t)
+		g.SetDurationFormat(tc.format)
+
+		sor := g.newSchemaFromType(rt(Y{}), tonic.MediaType())
+		assert.NotNil(t, sor)
+		assert.Empty(t, g.Errors())
+
+		n := g.resolveSchema(sor).Properties["N"]
+		if assert.NotNil(t, n) {
+			nc := n.Properties["Nc"]
+			if assert.NotNil(t, nc) {
+				assert.Equal(t, tc.typ, nc.Type)
+				assert.Equal(t, tc.fmt, nc.Format)
+			}
+		}
+		sor = g.newSchemaFromType(rt(T{}), tonic.MediaType())
+		if assert.NotNil(t, sor) {
+			items := g.resolveSchema(sor).Properties["Timeouts"].Items
+			assert.Equal(t, tc.typ, items.Type)
+			assert.Equal(t, tc.fmt, items.Format)
+		}
+	}
+}
+
+// TestSchemaFromRegisteredInterface tests that the schema
+// of an interface with registered implementations is one
+// of the schemas of the implementations.
+func TestSchemaFromRegisteredInterface(t *testing.T) {
+	type Drawing struct {
+		Shape  shape       `json:"shape"`
+		Shapes []shape     `json:"shapes"`
+		Any    interface{} `json:"any"`
+	}
+	g := gen(t)
+
+	err := g.SetInterfaceDiscriminator(rt((*shape)(nil)), "kind")
+	assert.NotNil(t, err)
+
+	err = g.RegisterInterfaceImplementations(rt(Circle{}), rt(Circle{}))
+	assert.NotNil(t, err)
+
+	err = g.RegisterInterfaceImplementations(rt((*shape)(nil)), rt(W{}))
+	assert.NotNil(t, err)
+
+	err = g.RegisterInterfaceImplementations(rt((*shape)(nil)), rt(Circle{}), rt(Square{}), rt(&Triangle{}))
+	assert.Nil(t, err)
+
+	err = g.SetInterfaceDiscriminator(rt((*shape)(nil)), "kind")
+	assert.Nil(t, err)
+
+	sor := g.newSchemaFromType(rt(Drawing{}), tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.Empty(t, g.Errors())
+
+	actual, err := json.Marshal(g.resolveSchema(sor))
+	if err != nil {
+		t.Error(err)
+	}
+	// see testdata/oneof.json.
+	expected, err := ioutil.ReadFile("../testdata/schemas/oneof.json")
+	if err != nil {
+		t.Error(err)
+	}
+	m, err := diffJSON(actual, expected)
+	if err != nil {
+		t.Error(err)
+	}
+	if !m {
+		t.Error("expected json outputs to be equal")
+	}
+	for _, name := range []string{"Circle", "Square", "Triangle"} {
+		assert.Contains(t, g.API().Components.Schemas, name)
+	}
+}
+
+// TestInterfaceDiscriminatorMapping tests that the values
+// of the discriminator of an interface are mapped to the
+// references of the schemas of its implementations.
+func TestInterfaceDiscriminatorMapping(t *testing.T) {
+	g := gen(t)
+	iface := rt((*shape)(nil))
+
+	err := g.AddInterfaceDiscriminatorMapping(iface, "circle", rt(Circle{}))
+	assert.NotNil(t, err)
+
+	err = g.RegisterInterfaceImplementations(iface, rt(Circle{}), rt(Square{}), rt(&Triangle{}))
+	assert.Nil(t, err)
+
+	// The discriminator must be set first.
+	err = g.AddInterfaceDiscriminatorMapping(iface, "circle", rt(Circle{}))
+	assert.NotNil(t, err)
+
+	err = g.SetInterfaceDiscriminator(iface, "kind")
+	assert.Nil(t, err)
+
+	for value, impl := range map[string]reflect.Type{
+		"circle":   rt(Circle{}),
+		"square":   rt(Square{}),
+		"triangle": rt(&Triangle{}),
+	} {
+		err = g.AddInterfaceDiscriminatorMapping(iface, value, impl)
+		assert.Nil(t, err)
+	}
+	err = g.AddInterfaceDiscriminatorMapping(iface, "", rt(Circle{}))
+	assert.NotNil(t, err)
+
+	err = g.AddInterfaceDiscriminatorMapping(iface, "payment", rt(CardPayment{}))
+	assert.NotNil(t, err)
+
+	sor := g.newSchemaFromType(iface, tonic.MediaType())
+	assert.Empty(t, g.Errors())
+
+	if assert.NotNil(t, sor) && assert.NotNil(t, sor.Discriminator) {
+		assert.Equal(t, &Discriminator{
+			PropertyName: "kind",
+			Mapping: map[string]string{
+				"circle":   "#/components/schemas/Circle",
+				"square":   "#/components/schemas/Square",
+				"triangle": "#/components/schemas/Triangle",
+			},
+		}, sor.Discriminator)
+	}
+}
+
+type (
+	CardPayment     struct{ Number string }
+	TransferPayment struct{ IBAN string }
+	payment         struct{ Value interface{} }
+)
+
+func (payment) Variants() []interface{} {
+	return []interface{}{CardPayment{}, &TransferPayment{}}
+}
+
+// TestSchemaFromVariants tests that the schema of a type
+// that implements the Variants interface is any of the
+// schemas of its variants.
+func TestSchemaFromVariants(t *testing.T) {
+	type Order struct {
+		Payment  *payment  `json:"payment"`
+		Payments []payment `json:"payments"`
+	}
+	g := gen(t)
+
+	sor := g.newSchemaFromType(rt(Order{}), tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.Empty(t, g.Errors())
+
+	schema := g.resolveSchema(sor)
+	assert.NotNil(t, schema)
+
+	p := schema.Properties["payment"].Schema
+	assert.NotNil(t, p)
+	assert.True(t, p.Nullable)
+	if assert.Len(t, p.AnyOf, 2) {
+		assert.Equal(t, componentsSchemaPath+"CardPayment", p.AnyOf[0].Ref)
+		assert.Equal(t, componentsSchemaPath+"TransferPayment", p.AnyOf[1].Ref)
+	}
+	items := schema.Properties["payments"].Schema.Items.Schema
+	assert.NotNil(t, items)
+	assert.False(t, items.Nullable)
+	assert.Len(t, items.AnyOf, 2)
+
+	for _, name := range []string{"CardPayment", "TransferPayment"} {
+		assert.Contains(t, g.API().Components.Schemas, name)
+	}
+}
+
+type Invoice struct {
+	Number string `json:"number" description:"Number of the invoice"`
+}
+
+func (Invoice) SchemaDescription() string { return "An invoice sent to a customer" }
+
+// TestSchemaDescription tests that the description of
+// a type that implements the SchemaDescriber interface
+// is set on its component schema.
+func TestSchemaDescription(t *testing.T) {
+	type Order struct {
+		Invoice *Invoice `json:"invoice"`
+	}
+	g := gen(t)
+
+	sor := g.newSchemaFromType(rt(Order{}), tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.Empty(t, g.Errors())
+
+	schemas := g.API().Components.Schemas
+	if assert.Contains(t, schemas, "Invoice") {
+		s := schemas["Invoice"].Schema
+		assert.Equal(t, "An invoice sent to a customer", s.Description)
+		assert.Equal(t, "Number of the invoice", s.Properties["number"].Description)
+	}
+	order := g.resolveSchema(sor)
+	assert.Empty(t, order.Description)
+	assert.NotNil(t, order.Properties["invoice"].Reference)
+}
+
 // TestNewSchemaFromStructErrors tests the errors
 // case of generation of a schema from a struct.
 func TestNewSchemaFromStructErrors(t *testing.T) {
@@ -392,35 +931,320 @@ func TestNewSchemaFromStructFieldFormat(t *testing.T) {
 	assert.Equal(t, sor.Schema.Format, "email")
 }
 
-func TestNewSchemaFromEnumField(t *testing.T) {
-	g := gen(t)
+type hexColor string
 
-	type T struct {
-		A string      `enum:"a,b,c"`
-		B int         `enum:"1,2,3"`
-		C *string     `enum:"d,e,f"`
-		D *int        `enum:"4,5,6"`
-		E []string    `enum:"g,h,i"`
-		F *[]string   `enum:"j,k,l"`
-		G **string    `enum:"m,n,o"`
-		H **[]string  `enum:"p,q,r"`
-		I **[]float64 `enum:"7.0,8.1,9.2"`
-	}
+func (hexColor) Type() string   { return "string" }
+func (hexColor) Format() string { return "color" }
 
-	tests := []struct {
-		fname        string
-		expectedEnum []interface{}
-		isSlice      bool
+// TestNewSchemaFromStructFieldValidatorFormat tests
+// that the validators of the format of a string set
+// the format of its schema.
+func TestNewSchemaFromStructFieldValidatorFormat(t *testing.T) {
+	g := gen(t)
+
+	for _, tc := range []struct {
+		field  interface{}
+		tag    string
+		format string
 	}{
-		{"A", []interface{}{"a", "b", "c"}, false},
-		{"B", []interface{}{int64(1), int64(2), int64(3)}, false},
-		{"C", []interface{}{"d", "e", "f"}, false},
-		{"D", []interface{}{int64(4), int64(5), int64(6)}, false},
-		{"E", []interface{}{"g", "h", "i"}, true},
-		{"F", []interface{}{"j", "k", "l"}, true},
-		{"G", []interface{}{"m", "n", "o"}, false},
-		{"H", []interface{}{"p", "q", "r"}, false},
-		{"I", []interface{}{7.0, 8.1, 9.2}, false},
+		{"", "email", "email"},
+		{"", "required,email,max=64", "email"},
+		{"", "url", "uri"},
+		{"", "uri", "uri"},
+		{"", "hostname", "hostname"},
+		{"", "hostname_rfc1123", "hostname"},
+		{"", "fqdn", "hostname"},
+		{"", "ip", "ip"},
+		{"", "ipv4", "ipv4"},
+		{"", "ipv6", "ipv6"},
+		{"", "uuid", "uuid"},
+		{"", "uuid4", "uuid"},
+		{"", "base64", "byte"},
+		{"", "datetime=2006-01-02", "date"},
+		{"", "datetime=2006-01-02T15:04:05Z07:00", "date-time"},
+		{"", "datetime=15:04", ""},
+		{"", "ipv4|ipv6", ""},
+		{new(string), "uuid", "uuid"},
+		{[]string{}, "uuid", ""},
+		{[]string{}, "dive,uuid", ""},
+		{time.Time{}, "email", "date-time"},
+		{hexColor(""), "hostname", "color"},
+	} {
+		sf := reflect.StructField{
+			Name: "A",
+			Type: rt(tc.field),
+			Tag:  reflect.StructTag(fmt.Sprintf(`validate:%q`, tc.tag)),
+		}
+		sor := g.newSchemaFromStructField(sf, false, "a", rt(struct{}{}), tonic.MediaType())
+		if assert.NotNil(t, sor, tc.tag) {
+			assert.Equal(t, tc.format, sor.Schema.Format, tc.tag)
+		}
+	}
+	assert.Empty(t, g.Errors())
+}
+
+// TestNewSchemaFromStructFieldIntegerString tests that
+// the integer fields with the int64-string format are
+// described as strings of digits.
+func TestNewSchemaFromStructFieldIntegerString(t *testing.T) {
+	g := gen(t)
+
+	type T struct {
+		ID     int64  `format:"int64-string"`
+		Parent *int64 `format:"int64-string"`
+		Count  uint64 `format:"int64-string"`
+		Size   int64  `json:"size"`
+		Name   string `format:"int64-string"` // invalid
+	}
+	typ := reflect.TypeOf(T{})
+
+	for _, tc := range []struct {
+		field   string
+		typ     string
+		format  string
+		pattern string
+	}{
+		{"ID", "string", "int64", "^-?[0-9]+$"},
+		{"Parent", "string", "int64", "^-?[0-9]+$"},
+		{"Count", "string", "int64", "^[0-9]+$"},
+		{"Size", "integer", "int64", ""},
+	} {
+		sf, _ := typ.FieldByName(tc.field)
+		sor := g.newSchemaFromStructField(sf, false, tc.field, typ, tonic.MediaType())
+		if assert.NotNil(t, sor, tc.field) {
+			assert.Equal(t, tc.typ, sor.Schema.Type, tc.field)
+			assert.Equal(t, tc.format, sor.Schema.Format, tc.field)
+			assert.Equal(t, tc.pattern, sor.Schema.Pattern, tc.field)
+		}
+	}
+	assert.Empty(t, g.Errors())
+
+	sf, _ := typ.FieldByName("Name")
+	sor := g.newSchemaFromStructField(sf, false, "Name", typ, tonic.MediaType())
+	if assert.NotNil(t, sor) {
+		assert.Equal(t, "string", sor.Schema.Type)
+		assert.Empty(t, sor.Schema.Pattern)
+	}
+	assert.Len(t, g.Errors(), 1)
+}
+
+// TestNewSchemaFromStructFieldIP tests that the format
+// of the IP addresses can be refined with the format
+// tag, and that the networks use the cidr format.
+func TestNewSchemaFromStructFieldIP(t *testing.T) {
+	g := gen(t)
+
+	type T struct {
+		Addr    net.IP
+		AddrV4  net.IP  `format:"ipv4"`
+		AddrV6  *net.IP `format:"ipv6"`
+		Network net.IPNet
+		Subnet  *net.IPNet
+	}
+	typ := reflect.TypeOf(T{})
+
+	for field, format := range map[string]string{
+		"Addr":    "ip",
+		"AddrV4":  "ipv4",
+		"AddrV6":  "ipv6",
+		"Network": "cidr",
+		"Subnet":  "cidr",
+	} {
+		sf, _ := typ.FieldByName(field)
+		sor := g.newSchemaFromStructField(sf, false, field, typ, tonic.MediaType())
+		if assert.NotNil(t, sor, field) && assert.NotNil(t, sor.Schema, field) {
+			assert.Equal(t, "string", sor.Schema.Type, field)
+			assert.Equal(t, format, sor.Schema.Format, field)
+		}
+	}
+	assert.Empty(t, g.Errors())
+}
+
+// TestNewSchemaFromStructFieldPassword tests that the
+// password fields are write-only strings with the
+// password format.
+func TestNewSchemaFromStructFieldPassword(t *testing.T) {
+	g := gen(t)
+
+	type T struct {
+		Password string  `format:"password"`
+		Secret   *string `validate:"required,password"`
+		Hash     string  `format:"password" readonly:"true"`
+		Token    string  `format:"password" writeonly:"false"`
+		PIN      int     `format:"password"`
+	}
+	typ := reflect.TypeOf(T{})
+
+	for _, tc := range []struct {
+		field     string
+		typ       string
+		format    string
+		writeOnly bool
+	}{
+		{"Password", "string", "password", true},
+		{"Secret", "string", "password", true},
+		{"Hash", "string", "password", false},
+		{"Token", "string", "password", false},
+		{"PIN", "integer", "password", false},
+	} {
+		sf, _ := typ.FieldByName(tc.field)
+		sor := g.newSchemaFromStructField(sf, false, tc.field, typ, tonic.MediaType())
+		if assert.NotNil(t, sor, tc.field) {
+			assert.Equal(t, tc.typ, sor.Schema.Type, tc.field)
+			assert.Equal(t, tc.format, sor.Schema.Format, tc.field)
+			assert.Equal(t, tc.writeOnly, sor.Schema.WriteOnly, tc.field)
+		}
+	}
+	assert.Empty(t, g.Errors())
+}
+
+// TestNewSchemaFromStructFieldPattern tests that the
+// pattern of a string field is set from the pattern tag
+// or from the validator tag.
+func TestNewSchemaFromStructFieldPattern(t *testing.T) {
+	g := gen(t)
+
+	type T struct {
+		A string  `pattern:"^[a-z0-9_]+$"`
+		B *string `validate:"required,alphanum"`
+		C string  `validate:"hexadecimal"`
+		D string  `validate:"alpha|numeric"` // ignored, alternative between validators
+		E int     `validate:"numeric"`       // ignored, not a string
+		F int     `pattern:"^[0-9]+$"`
+	}
+	typ := reflect.TypeOf(T{})
+
+	tests := []struct {
+		fname   string
+		pattern string
+	}{
+		{"A", "^[a-z0-9_]+$"},
+		{"B", validatorPatterns["alphanum"]},
+		{"C", validatorPatterns["hexadecimal"]},
+		{"D", ""},
+		{"E", ""},
+	}
+	for i, tt := range tests {
+		sor := g.newSchemaFromStructField(typ.Field(i), false, tt.fname, typ, tonic.MediaType())
+		assert.NotNil(t, sor)
+		assert.Equal(t, tt.pattern, sor.Pattern, tt.fname)
+	}
+	assert.Empty(t, g.Errors())
+
+	// Field F is not a string and cannot have a pattern.
+	sor := g.newSchemaFromStructField(typ.Field(5), false, "F", typ, tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.Empty(t, sor.Pattern)
+	assert.Len(t, g.Errors(), 1)
+
+	fe, ok := g.Errors()[0].(*FieldError)
+	assert.True(t, ok)
+	assert.Equal(t, "F", fe.Name)
+}
+
+// TestNewSchemaFromStructFieldBinaryFormat tests that
+// the format tag overrides the default byte format of a
+// []byte field, without changing the one of other fields.
+func TestNewSchemaFromStructFieldBinaryFormat(t *testing.T) {
+	g := gen(t)
+
+	type T struct {
+		File []byte `json:"file" format:"binary"`
+		Data []byte `json:"data"`
+	}
+	sor := g.newSchemaFromType(reflect.TypeOf(T{}), tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.Empty(t, g.Errors())
+
+	schema := g.resolveSchema(sor)
+	if assert.NotNil(t, schema) {
+		file := g.resolveSchema(schema.Properties["file"])
+		assert.Equal(t, "string", file.Type)
+		assert.Equal(t, "binary", file.Format)
+
+		data := g.resolveSchema(schema.Properties["data"])
+		assert.Equal(t, "string", data.Type)
+		assert.Equal(t, "byte", data.Format)
+	}
+}
+
+// TestNewSchemaFromStructFieldReadWriteOnly tests that
+// the readonly and writeonly tags of a struct field are
+// reflected in the generated schema.
+func TestNewSchemaFromStructFieldReadWriteOnly(t *testing.T) {
+	g := gen(t)
+
+	type T struct {
+		ID       string `json:"id" readonly:"true"`
+		Password string `json:"password" writeonly:"true"`
+		Name     string `json:"name"`
+	}
+	sor := g.newSchemaFromType(reflect.TypeOf(T{}), tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.Empty(t, g.Errors())
+
+	b, err := json.Marshal(g.resolveSchema(sor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, m.Properties["id"]["readOnly"])
+	assert.NotContains(t, m.Properties["id"], "writeOnly")
+	assert.Equal(t, true, m.Properties["password"]["writeOnly"])
+	assert.NotContains(t, m.Properties["password"], "readOnly")
+	assert.NotContains(t, m.Properties["name"], "readOnly")
+	assert.NotContains(t, m.Properties["name"], "writeOnly")
+
+	// A field cannot be both read-only and write-only.
+	type U struct {
+		A string `readonly:"true" writeonly:"true"`
+	}
+	typ := reflect.TypeOf(U{})
+	sor = g.newSchemaFromStructField(typ.Field(0), false, "A", typ, tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.False(t, sor.ReadOnly)
+	assert.False(t, sor.WriteOnly)
+	assert.Len(t, g.Errors(), 1)
+
+	fe, ok := g.Errors()[0].(*FieldError)
+	assert.True(t, ok)
+	assert.Equal(t, "A", fe.Name)
+}
+
+func TestNewSchemaFromEnumField(t *testing.T) {
+	g := gen(t)
+
+	type T struct {
+		A string      `enum:"a,b,c"`
+		B int         `enum:"1,2,3"`
+		C *string     `enum:"d,e,f"`
+		D *int        `enum:"4,5,6"`
+		E []string    `enum:"g,h,i"`
+		F *[]string   `enum:"j,k,l"`
+		G **string    `enum:"m,n,o"`
+		H **[]string  `enum:"p,q,r"`
+		I **[]float64 `enum:"7.0,8.1,9.2"`
+	}
+
+	tests := []struct {
+		fname        string
+		expectedEnum []interface{}
+		isSlice      bool
+	}{
+		{"A", []interface{}{"a", "b", "c"}, false},
+		{"B", []interface{}{int64(1), int64(2), int64(3)}, false},
+		{"C", []interface{}{"d", "e", "f"}, false},
+		{"D", []interface{}{int64(4), int64(5), int64(6)}, false},
+		{"E", []interface{}{"g", "h", "i"}, true},
+		{"F", []interface{}{"j", "k", "l"}, true},
+		{"G", []interface{}{"m", "n", "o"}, false},
+		{"H", []interface{}{"p", "q", "r"}, false},
+		{"I", []interface{}{7.0, 8.1, 9.2}, false},
 	}
 
 	typ := reflect.TypeOf(T{})
@@ -441,6 +1265,50 @@ func TestNewSchemaFromEnumField(t *testing.T) {
 	}
 }
 
+// TestNewSchemaFromEnumFieldDescriptions tests that the
+// descriptions of the enum values of a field are added to
+// its schema, and that their number must match the values.
+func TestNewSchemaFromEnumFieldDescriptions(t *testing.T) {
+	g := gen(t)
+
+	type T struct {
+		A string   `enum:"active,blocked,closed" enumDescriptions:"Active,Blocked,Closed"`
+		B []string `enum:"r,w" enumDescriptions:"Read,Write"`
+		C string   `enum:"a,b,c" enumDescriptions:"A,B"`
+		D string   `enumDescriptions:"A"`
+	}
+	typ := reflect.TypeOf(T{})
+
+	sor := g.newSchemaFromStructField(typ.Field(0), false, "a", typ, tonic.MediaType())
+	if assert.NotNil(t, sor) {
+		assert.Equal(t, []string{"Active", "Blocked", "Closed"}, sor.XEnumDescs)
+
+		b, err := json.Marshal(sor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Contains(t, string(b), `"x-enum-descriptions":["Active","Blocked","Closed"]`)
+	}
+	sor = g.newSchemaFromStructField(typ.Field(1), false, "b", typ, tonic.MediaType())
+	if assert.NotNil(t, sor) && assert.NotNil(t, sor.Items) {
+		assert.Equal(t, []string{"Read", "Write"}, sor.Items.XEnumDescs)
+	}
+	assert.Empty(t, g.Errors())
+
+	for i, name := range []string{"c", "d"} {
+		sor = g.newSchemaFromStructField(typ.Field(2+i), false, name, typ, tonic.MediaType())
+		if assert.NotNil(t, sor) {
+			assert.Nil(t, sor.XEnumDescs)
+		}
+		if assert.Len(t, g.Errors(), i+1) {
+			fe, ok := g.Errors()[i].(*FieldError)
+			if assert.True(t, ok) {
+				assert.Equal(t, name, fe.Name)
+			}
+		}
+	}
+}
+
 func diffJSON(a, b []byte) (bool, error) {
 	var j, j2 interface{}
 	if err := json.Unmarshal(a, &j); err != nil {
@@ -617,6 +1485,80 @@ func TestSetInfo(t *testing.T) {
 	assert.Equal(t, infos, g.API().Info)
 }
 
+// TestSetContactAndLicense tests that the contact
+// and the license are set in the info of the spec.
+func TestSetContactAndLicense(t *testing.T) {
+	g := gen(t)
+	g.SetInfo(nil)
+
+	g.SetContact("API Support", "https://example.com/support", "support@example.com")
+	g.SetLicense("Apache 2.0", "https://www.apache.org/licenses/LICENSE-2.0.html")
+
+	b, err := json.Marshal(g.API().Info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{
+		"title": "",
+		"version": "",
+		"contact": {
+			"name": "API Support",
+			"url": "https://example.com/support",
+			"email": "support@example.com"
+		},
+		"license": {
+			"name": "Apache 2.0",
+			"url": "https://www.apache.org/licenses/LICENSE-2.0.html"
+		}
+	}`, string(b))
+
+	// The other fields of the info are kept.
+	g.SetInfo(&Info{Title: "Test", Version: "1.0.0"})
+	g.SetLicense("MIT", "")
+
+	info := g.API().Info
+	assert.Equal(t, "Test", info.Title)
+	assert.Equal(t, &License{Name: "MIT"}, info.License)
+	assert.Nil(t, info.Contact)
+}
+
+// TestSetTermsOfServiceAndSummary tests that the terms
+// of service and the summary are set in the info of the
+// spec, which is created if needed.
+func TestSetTermsOfServiceAndSummary(t *testing.T) {
+	g := gen(t)
+	g.SetInfo(nil)
+
+	g.SetTermsOfService("https://example.com/terms")
+	g.SetInfoSummary("A pet store")
+
+	b, err := json.Marshal(g.API())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec struct {
+		Info json.RawMessage `json:"info"`
+	}
+	if err := json.Unmarshal(b, &spec); err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{
+		"title": "",
+		"summary": "A pet store",
+		"version": "",
+		"termsOfService": "https://example.com/terms"
+	}`, string(spec.Info))
+
+	// The other fields of the info are kept.
+	g.SetInfo(&Info{Title: "Test", Version: "1.0.0"})
+	g.SetInfoSummary("Test API")
+
+	info := g.API().Info
+	assert.Equal(t, "Test", info.Title)
+	assert.Equal(t, "Test API", info.Summary)
+	assert.Empty(t, info.TermsOfService)
+}
+
 // TestSetOperationByMethod tests that an operation
 // is added to a path item accordingly to the given
 // HTTP method.
@@ -725,6 +1667,43 @@ func TestSetOperationResponseExamples(t *testing.T) {
 	assert.Nil(t, mt.Example)
 }
 
+// TestSetOperationResponseHeaders tests that the
+// headers of a response carry their properties.
+func TestSetOperationResponseHeaders(t *testing.T) {
+	g := gen(t)
+	op := &Operation{
+		Responses: make(Responses),
+	}
+	err := g.setOperationResponse(op, nil, "200", "application/json", "", []*ResponseHeader{{
+		Name:        "X-RateLimit-Remaining",
+		Description: "Number of requests left",
+		Model:       int(0),
+		Required:    true,
+		Example:     42,
+	}, {
+		Name:       "X-Legacy",
+		Deprecated: true,
+	}}, nil, nil)
+	assert.Nil(t, err)
+
+	b, err := json.Marshal(op.Responses["200"].Headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{
+		"X-RateLimit-Remaining": {
+			"description": "Number of requests left",
+			"required": true,
+			"schema": {"type": "integer", "format": "int32"},
+			"example": 42
+		},
+		"X-Legacy": {
+			"deprecated": true,
+			"schema": {"type": "string"}
+		}
+	}`, string(b))
+}
+
 // TestSetOperationParamsError tests the various error
 // cases that can occur while adding parameters to an op.
 func TestSetOperationParamsError(t *testing.T) {
@@ -745,11 +1724,52 @@ func TestSetOperationParamsError(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+// TestParameterAndPropertyNames tests that the name of
+// a parameter comes from its location tag, and that the
+// name of the property of a body comes from the json tag.
+func TestParameterAndPropertyNames(t *testing.T) {
+	type Filter struct {
+		Status string `json:"foo" query:"bar"`
+	}
+	type In struct {
+		Filter
+		Name string `json:"name"`
+	}
+	g := gen(t)
+
+	op, err := g.AddOperation("/items", "POST", "", tonic.MediaType(), tonic.MediaType(), rt(&In{}), rt(Filter{}), &OperationInfo{
+		ID:         "CreateItem",
+		StatusCode: 200,
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	if assert.Len(t, op.Parameters, 1) {
+		assert.Equal(t, "bar", op.Parameters[0].Name)
+		assert.Equal(t, "query", op.Parameters[0].In)
+	}
+	// The parameter is not part of the request body.
+	body := g.resolveSchema(op.RequestBody.Content["application/json"].Schema)
+	if assert.NotNil(t, body) {
+		assert.Contains(t, body.Properties, "name")
+		assert.NotContains(t, body.Properties, "foo")
+		assert.NotContains(t, body.Properties, "bar")
+	}
+	// The same field in a response body is a property.
+	resp := g.resolveSchema(op.Responses["200"].Content["application/json"].Schema)
+	if assert.NotNil(t, resp) {
+		assert.Contains(t, resp.Properties, "foo")
+		assert.NotContains(t, resp.Properties, "bar")
+	}
+}
+
 // TestParamLocationConflict tests that using conflicting
 // locations in the tag of a parameter throws an error.
 func TestParamLocationConflict(t *testing.T) {
 	type T struct {
 		A string `path:"a" query:"b"`
+		B string `cookie:"b" header:"b"`
+		C string `cookie:"c"`
 	}
 	g := gen(t)
 
@@ -757,94 +1777,547 @@ func TestParamLocationConflict(t *testing.T) {
 		g.config.PathLocationTag,
 		g.config.QueryLocationTag,
 		g.config.HeaderLocationTag,
+		g.config.CookieLocationTag,
 	}
-	_, err := g.paramLocation(reflect.TypeOf(T{}).Field(0), parameterLocations, reflect.TypeOf(T{}))
-	assert.NotNil(t, err)
-}
-
-// TestOverrideDataType tests that the data type
-// of a type can be ovirriden manually.
-func TestOverrideSchema(t *testing.T) {
-	g := gen(t)
+	typ := reflect.TypeOf(T{})
 
-	// Type is mandatory.
-	err := g.OverrideDataType(rt(W{}), "", "wallet")
+	_, err := g.paramLocation(typ.Field(0), parameterLocations, typ)
 	assert.NotNil(t, err)
 
-	// Success.
-	err = g.OverrideDataType(rt(&W{}), "string", "wallet")
-	assert.Nil(t, err)
-
-	// Data type already overidden.
-	err = g.OverrideDataType(rt(&W{}), "string", "wallet")
+	_, err = g.paramLocation(typ.Field(1), parameterLocations, typ)
 	assert.NotNil(t, err)
 
-	sor := g.newSchemaFromType(rt(W{}), tonic.MediaType())
-	assert.NotNil(t, sor)
-
-	schema := g.resolveSchema(sor)
-	assert.NotNil(t, schema)
-
-	assert.Equal(t, "string", schema.Type)
-	assert.Equal(t, "wallet", schema.Format)
-}
-
-// TestNewGenWithoutConfig tests that creating a
-// new generator without config fails.
-func TestNewGenWithoutConfig(t *testing.T) {
-	_, err := NewGenerator(nil)
-	assert.NotNil(t, err)
+	loc, err := g.paramLocation(typ.Field(2), parameterLocations, typ)
+	assert.Nil(t, err)
+	assert.Equal(t, "cookie", loc)
 }
 
-// TestSetServers tests that a custom servers description
-// can be added to the specification and is properly marshaled.
-func TestSetServers(t *testing.T) {
+// TestDeprecatedParameter tests that the deprecated
+// tag of a struct field is set on its parameter.
+func TestDeprecatedParameter(t *testing.T) {
+	type In struct {
+		A string `query:"a" deprecated:"true"`
+		B string `query:"b"`
+	}
 	g := gen(t)
 
-	servers := []*Server{
-		{
-			URL:         "https://dev.api.foo.bar/v1",
-			Description: "Development server",
-		},
-		{
-			URL:         "https://prod.api.foo.bar/{basePath}",
-			Description: "Production server",
-			Variables: map[string]*ServerVariable{
-				"basePath": {
-					Description: "Version of the API",
-					Enum: []string{
-						"v1", "v2", "beta",
-					},
-					Default: "v2",
-				},
-			},
-		},
+	_, err := g.AddOperation("/test", "GET", "Test", "", tonic.MediaType(), rt(In{}), nil, &OperationInfo{
+		ID:         "Test",
+		StatusCode: 200,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := g.API().Paths["/test"].GET.Parameters
+	if assert.Len(t, params, 2) {
+		assert.Equal(t, "a", params[0].Name)
+		assert.True(t, params[0].Deprecated)
+		assert.Equal(t, "b", params[1].Name)
+		assert.False(t, params[1].Deprecated)
 	}
-	g.SetServers(servers)
-
-	assert.NotNil(t, g.API().Servers)
-	assert.Equal(t, servers, g.API().Servers)
 }
 
-type customUnit float64
+// TestCookieParameter tests that a struct field
+// with a cookie tag is described as a parameter
+// located in a cookie.
+func TestCookieParameter(t *testing.T) {
+	type T struct {
+		Session string `cookie:"session" validate:"required" description:"Session identifier"`
+	}
+	g := gen(t)
 
-func (c customUnit) ParseExample(v string) (interface{}, error) {
-	s, err := strconv.ParseFloat(v, 64)
-	if err != nil {
-		return nil, err
+	p, loc, err := g.newParameterFromField(0, reflect.TypeOf(T{}), tonic.MediaType())
+	assert.Nil(t, err)
+	assert.Equal(t, "cookie", loc)
+	if assert.NotNil(t, p) {
+		assert.Equal(t, "session", p.Name)
+		assert.Equal(t, "cookie", p.In)
+		assert.Equal(t, "Session identifier", p.Description)
+		assert.True(t, p.Required)
 	}
-	return fmt.Sprintf("%.2f USD", s), nil
 }
 
-type customTime time.Time
-
-func (c customTime) ParseExample(v string) (interface{}, error) {
-	t1, err := time.Parse(time.RFC3339, v)
-	if err != nil {
-		return nil, err
+// TestParameterStyle tests that the style and explode
+// tags describe the serialization of a parameter.
+func TestParameterStyle(t *testing.T) {
+	type T struct {
+		A []string `query:"a"`
+		B []string `query:"b" style:"pipeDelimited"`
+		C []string `query:"c" style:"form" explode:"false"`
+		D []string `query:"d" style:"spaceDelimited" explode:"true"`
+		E string   `path:"e" style:"label"`
+		F string   `header:"X-F" style:"form"` // invalid
 	}
-	return customTime(t1), nil
-}
+	tests := []struct {
+		fname    string
+		expected string
+	}{
+		{"A", `{"name":"a","in":"query","schema":{"type":"array","items":{"type":"string"}},"style":"form","explode":true}`},
+		{"B", `{"name":"b","in":"query","schema":{"type":"array","items":{"type":"string"}},"style":"pipeDelimited"}`},
+		{"C", `{"name":"c","in":"query","schema":{"type":"array","items":{"type":"string"}},"style":"form"}`},
+		{"D", `{"name":"d","in":"query","schema":{"type":"array","items":{"type":"string"}},"style":"spaceDelimited","explode":true}`},
+		{"E", `{"name":"e","in":"path","required":true,"schema":{"type":"string"},"style":"label"}`},
+	}
+	typ := reflect.TypeOf(T{})
+
+	for i, tt := range tests {
+		t.Run(tt.fname, func(t *testing.T) {
+			g := gen(t)
+
+			p, _, err := g.newParameterFromField(i, typ, tonic.MediaType())
+			assert.Nil(t, err)
+			assert.Empty(t, g.Errors())
+
+			b, err := json.Marshal(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := diffJSON(b, []byte(tt.expected))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.True(t, m, string(b))
+		})
+	}
+	g := gen(t)
+
+	p, _, err := g.newParameterFromField(5, typ, tonic.MediaType())
+	assert.Nil(t, err)
+	assert.Empty(t, p.Style)
+	assert.Len(t, g.Errors(), 1)
+}
+
+// TestArrayQueryStyle tests that the array query parameters
+// without a style tag use the default style of the generator.
+func TestArrayQueryStyle(t *testing.T) {
+	type In struct {
+		A []string `query:"a" validate:"max=2"`
+		B []string `query:"b" style:"form"`
+		C string   `query:"c"`
+	}
+	g := gen(t)
+
+	err := g.SetArrayQueryStyle("deepObject", true)
+	assert.NotNil(t, err)
+
+	err = g.SetArrayQueryStyle("label", false)
+	assert.NotNil(t, err)
+
+	err = g.SetArrayQueryStyle("pipeDelimited", false)
+	assert.Nil(t, err)
+
+	op, err := g.AddOperation("/items", "GET", "", tonic.MediaType(), tonic.MediaType(), rt(&In{}), nil, &OperationInfo{
+		ID:         "ListItems",
+		StatusCode: 200,
+	})
+	if !assert.Nil(t, err) || !assert.Len(t, op.Parameters, 3) {
+		return
+	}
+	assert.Equal(t, "pipeDelimited", op.Parameters[0].Style)
+	assert.False(t, op.Parameters[0].Explode)
+	assert.Equal(t, "form", op.Parameters[1].Style)
+	assert.True(t, op.Parameters[1].Explode)
+	assert.Empty(t, op.Parameters[2].Style)
+
+	// The values are split with the delimiter of the style.
+	r := httptest.NewRequest(http.MethodGet, "/items?a=x|y&b=z", nil)
+	assert.Empty(t, g.ValidateRequest(op, r, nil))
+
+	r = httptest.NewRequest(http.MethodGet, "/items?a=x|y|z", nil)
+	assert.Len(t, g.ValidateRequest(op, r, nil), 1)
+}
+
+// TestDeepObjectParameter tests that a struct-typed query
+// parameter with the deepObject style is described by an
+// exploded object parameter, and that its properties are
+// validated from the keys of the query string.
+func TestDeepObjectParameter(t *testing.T) {
+	type Filter struct {
+		Status string `json:"status" enum:"active,inactive"`
+		Limit  int    `json:"limit" validate:"max=100"`
+	}
+	type In struct {
+		Filter Filter `query:"filter" style:"deepObject" validate:"required"`
+		Sort   string `query:"sort" style:"deepObject"` // invalid
+	}
+	g := gen(t)
+
+	op, err := g.AddOperation("/items", "GET", "", tonic.MediaType(), tonic.MediaType(), rt(&In{}), nil, &OperationInfo{
+		ID:         "ListItems",
+		StatusCode: 200,
+	})
+	if !assert.Nil(t, err) || !assert.Len(t, op.Parameters, 2) {
+		return
+	}
+	assert.Len(t, g.Errors(), 1)
+
+	p := op.Parameters[0].Parameter
+	assert.Equal(t, "filter", p.Name)
+	assert.Equal(t, "deepObject", p.Style)
+	assert.True(t, p.Explode)
+	assert.Empty(t, op.Parameters[1].Style)
+
+	s := g.resolveSchema(p.Schema)
+	if assert.NotNil(t, s) {
+		assert.Equal(t, "object", s.Type)
+		assert.Len(t, s.Properties, 2)
+		assert.Equal(t, []interface{}{"active", "inactive"}, s.Properties["status"].Enum)
+		assert.Equal(t, "integer", s.Properties["limit"].Type)
+	}
+	for query, n := range map[string]int{
+		"filter[status]=active&filter[limit]=10": 0,
+		"filter[status]=closed&filter[limit]=10": 1,
+		"filter[limit]=200":                      1,
+		"filter[limit]=ten":                      1,
+		"sort=name":                              1,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/items?"+query, nil)
+		assert.Len(t, g.ValidateRequest(op, r, nil), n, query)
+	}
+}
+
+// TestSkippedParameters tests that the fields with
+// a "-" location tag name or with binding disabled
+// are neither parameters nor request body fields.
+func TestSkippedParameters(t *testing.T) {
+	type Embed struct {
+		E string `query:"e"`
+		F string `query:"-"`
+	}
+	type In struct {
+		*Embed
+		A string `query:"a"`
+		B string `query:"-"`
+		C string `query:"c" binding:"-"`
+		D string `json:"d" binding:"-"`
+		G string `json:"g"`
+	}
+	g := gen(t)
+
+	op, err := g.AddOperation("/test", "POST", "", tonic.MediaType(), tonic.MediaType(), rt(In{}), nil, &OperationInfo{
+		ID:         "Skipped",
+		StatusCode: 200,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range op.Parameters {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch(t, []string{"a", "e"}, names)
+
+	sor := g.API().Components.Schemas["SkippedInput"]
+	if assert.NotNil(t, sor) {
+		assert.Len(t, sor.Properties, 1)
+		assert.Contains(t, sor.Properties, "g")
+	}
+}
+
+// TestBindingTag tests that the fields with the
+// required option of the binding tag of Gin are
+// required, and the ones with a "-" are skipped,
+// including in the schemas of the nested types.
+func TestBindingTag(t *testing.T) {
+	type Owner struct {
+		Name  string   `json:"name" binding:"required"`
+		Tags  []string `json:"tags" binding:"dive,required"`
+		Email string   `json:"email" binding:"omitempty,email"`
+		Token string   `json:"token" binding:"-"`
+	}
+	type In struct {
+		Q     string `query:"q" binding:"required"`
+		Owner *Owner `json:"owner" binding:"required"`
+		Note  string `json:"note"`
+		ID    string `json:"id" binding:"-"`
+	}
+	g := gen(t)
+
+	op, err := g.AddOperation("/owners", "POST", "", tonic.MediaType(), tonic.MediaType(), rt(In{}), nil, &OperationInfo{
+		ID:         "CreateOwner",
+		StatusCode: 201,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, op.Parameters, 1) {
+		assert.Equal(t, "q", op.Parameters[0].Name)
+		assert.True(t, op.Parameters[0].Required)
+	}
+	schemas := g.API().Components.Schemas
+
+	if sor := schemas["CreateOwnerInput"]; assert.NotNil(t, sor) {
+		assert.Equal(t, []string{"owner"}, sor.Required)
+		assert.Contains(t, sor.Properties, "note")
+		assert.NotContains(t, sor.Properties, "id")
+	}
+	if sor := schemas["Owner"]; assert.NotNil(t, sor) {
+		assert.Equal(t, []string{"name"}, sor.Required)
+		assert.NotContains(t, sor.Properties, "token")
+	}
+}
+
+// TestOverrideDataType tests that the data type
+// of a type can be ovirriden manually.
+func TestOverrideSchema(t *testing.T) {
+	g := gen(t)
+
+	// Type is mandatory.
+	err := g.OverrideDataType(rt(W{}), "", "wallet")
+	assert.NotNil(t, err)
+
+	// Success.
+	err = g.OverrideDataType(rt(&W{}), "string", "wallet")
+	assert.Nil(t, err)
+
+	// Data type already overidden.
+	err = g.OverrideDataType(rt(&W{}), "string", "wallet")
+	assert.NotNil(t, err)
+
+	sor := g.newSchemaFromType(rt(W{}), tonic.MediaType())
+	assert.NotNil(t, sor)
+
+	schema := g.resolveSchema(sor)
+	assert.NotNil(t, schema)
+
+	assert.Equal(t, "string", schema.Type)
+	assert.Equal(t, "wallet", schema.Format)
+}
+
+// TestOverrideFieldSchema tests that the schema of a
+// single struct field can be overridden without changing
+// the schemas of the other fields of the same type.
+func TestOverrideFieldSchema(t *testing.T) {
+	type T struct {
+		Status string `json:"status" description:"The status"`
+		Name   string `json:"name"`
+	}
+	type U struct {
+		Status string `json:"status"`
+	}
+	g := gen(t)
+
+	err := g.OverrideFieldSchema(rt(T{}), "Status", nil)
+	assert.NotNil(t, err)
+
+	err = g.OverrideFieldSchema(rt(""), "Status", &Schema{Type: "string"})
+	assert.NotNil(t, err)
+
+	err = g.OverrideFieldSchema(rt(T{}), "Unknown", &Schema{Type: "string"})
+	assert.NotNil(t, err)
+
+	enum := &Schema{Type: "string", Enum: []interface{}{"active", "inactive"}}
+	err = g.OverrideFieldSchema(rt(&T{}), "Status", enum)
+	assert.Nil(t, err)
+
+	// Field schema already overridden.
+	err = g.OverrideFieldSchema(rt(T{}), "Status", enum)
+	assert.NotNil(t, err)
+
+	ts := g.resolveSchema(g.newSchemaFromType(rt(T{}), tonic.MediaType()))
+	us := g.resolveSchema(g.newSchemaFromType(rt(U{}), tonic.MediaType()))
+	assert.Empty(t, g.Errors())
+
+	if assert.NotNil(t, ts) && assert.NotNil(t, us) {
+		assert.Equal(t, []interface{}{"active", "inactive"}, ts.Properties["status"].Enum)
+		assert.Equal(t, "The status", ts.Properties["status"].Description)
+		assert.Empty(t, ts.Properties["name"].Enum)
+		assert.Empty(t, us.Properties["status"].Enum)
+	}
+	// The registered schema is left unchanged.
+	assert.Empty(t, enum.Description)
+}
+
+// TestOverrideTimeDataType tests that the data type of
+// time.Time can be overridden, for example to describe
+// times serialized as Unix epoch integers.
+func TestOverrideTimeDataType(t *testing.T) {
+	type T struct {
+		A time.Time   `json:"a"`
+		B *time.Time  `json:"b"`
+		C []time.Time `json:"c"`
+	}
+	g := gen(t)
+
+	err := g.OverrideDataType(rt(time.Time{}), TypeLong.Type(), TypeLong.Format())
+	assert.Nil(t, err)
+
+	sor := g.newSchemaFromType(rt(T{}), tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.Empty(t, g.Errors())
+
+	schema := g.resolveSchema(sor)
+	if !assert.NotNil(t, schema) {
+		return
+	}
+	for _, s := range []*Schema{
+		schema.Properties["a"].Schema,
+		schema.Properties["b"].Schema,
+		schema.Properties["c"].Schema.Items.Schema,
+	} {
+		assert.Equal(t, "integer", s.Type)
+		assert.Equal(t, "int64", s.Format)
+	}
+	// Without override, times are RFC3339 strings.
+	sor = gen(t).newSchemaFromType(rt(time.Time{}), tonic.MediaType())
+	assert.Equal(t, "string", sor.Type)
+	assert.Equal(t, "date-time", sor.Format)
+}
+
+// TestOverrideFullSchema tests that a custom schema
+// registered for a type is used for each occurrence.
+func TestOverrideFullSchema(t *testing.T) {
+	type Money struct {
+		Amount   int64
+		Currency string
+	}
+	type T struct {
+		A Money   `json:"a" description:"Price"`
+		B *Money  `json:"b"`
+		C []Money `json:"c"`
+	}
+	g := gen(t)
+
+	err := g.OverrideSchema(rt(Money{}), nil)
+	assert.NotNil(t, err)
+
+	err = g.OverrideSchema(rt(&Money{}), &Schema{
+		Type:        "string",
+		Pattern:     `^[0-9]+\.[0-9]{2} [A-Z]{3}$`,
+		Example:     "12.50 EUR",
+		Description: "An amount of money",
+	})
+	assert.Nil(t, err)
+
+	// Type already overridden.
+	err = g.OverrideSchema(rt(Money{}), &Schema{Type: "string"})
+	assert.NotNil(t, err)
+	err = g.OverrideDataType(rt(Money{}), "string", "money")
+	assert.NotNil(t, err)
+
+	sor := g.newSchemaFromType(rt(T{}), tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.Empty(t, g.Errors())
+
+	schema := g.resolveSchema(sor)
+	if !assert.NotNil(t, schema) {
+		return
+	}
+	a := schema.Properties["a"].Schema
+	b := schema.Properties["b"].Schema
+	c := schema.Properties["c"].Schema.Items.Schema
+
+	for _, s := range []*Schema{a, b, c} {
+		if assert.NotNil(t, s) {
+			assert.Equal(t, "string", s.Type)
+			assert.Equal(t, `^[0-9]+\.[0-9]{2} [A-Z]{3}$`, s.Pattern)
+			assert.Equal(t, "12.50 EUR", s.Example)
+		}
+	}
+	// The tags of a field apply to a copy of the schema.
+	assert.Equal(t, "Price", a.Description)
+	assert.Equal(t, "An amount of money", b.Description)
+	assert.Equal(t, "An amount of money", g.overrides[rt(Money{})].Description)
+
+	assert.False(t, a.Nullable)
+	assert.True(t, b.Nullable)
+}
+
+// TestNewGenWithoutConfig tests that creating a
+// new generator without config fails.
+func TestNewGenWithoutConfig(t *testing.T) {
+	_, err := NewGenerator(nil)
+	assert.NotNil(t, err)
+}
+
+// TestSetServers tests that a custom servers description
+// can be added to the specification and is properly marshaled.
+func TestSetServers(t *testing.T) {
+	g := gen(t)
+
+	servers := []*Server{
+		{
+			URL:         "https://dev.api.foo.bar/v1",
+			Description: "Development server",
+		},
+		{
+			URL:         "https://prod.api.foo.bar/{basePath}",
+			Description: "Production server",
+			Variables: map[string]*ServerVariable{
+				"basePath": {
+					Description: "Version of the API",
+					Enum: []string{
+						"v1", "v2", "beta",
+					},
+					Default: "v2",
+				},
+			},
+		},
+	}
+	g.SetServers(servers)
+
+	assert.NotNil(t, g.API().Servers)
+	assert.Equal(t, servers, g.API().Servers)
+}
+
+// TestAddSecurityScheme tests that security schemes
+// can be registered in the components of the spec.
+func TestAddSecurityScheme(t *testing.T) {
+	g := gen(t)
+
+	schemes := map[string]*SecurityScheme{
+		"apiKey": {Type: "apiKey", In: "cookie", Name: "session"},
+		"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		"basic":  {Type: "http", Scheme: "basic"},
+		"oauth2": {Type: "oauth2", Flows: &OAuthFlows{
+			ClientCredentials: &OAuthFlow{
+				TokenURL: "https://example.com/oauth/token",
+				Scopes:   map[string]string{"read": "read access"},
+			},
+		}},
+	}
+	for name, scheme := range schemes {
+		assert.Nil(t, g.AddSecurityScheme(name, scheme))
+	}
+	assert.Len(t, g.API().Components.SecuritySchemes, 4)
+	assert.Equal(t, schemes["bearer"], g.API().Components.SecuritySchemes["bearer"].SecurityScheme)
+
+	for _, scheme := range []*SecurityScheme{
+		nil,
+		{Type: "apiKey", In: "body", Name: "key"},
+		{Type: "apiKey", In: "header"},
+		{Type: "http"},
+		{Type: "oauth2"},
+		{Type: "openIdConnect"},
+		{Type: "unknown"},
+	} {
+		assert.NotNil(t, g.AddSecurityScheme("invalid", scheme))
+	}
+	assert.NotNil(t, g.AddSecurityScheme("", schemes["basic"]))
+
+	g.SetGlobalSecurity(&SecurityRequirement{"bearer": []string{}})
+	assert.Len(t, g.API().Security, 1)
+}
+
+type customUnit float64
+
+func (c customUnit) ParseExample(v string) (interface{}, error) {
+	s, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("%.2f USD", s), nil
+}
+
+type customTime time.Time
+
+func (c customTime) ParseExample(v string) (interface{}, error) {
+	t1, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, err
+	}
+	return customTime(t1), nil
+}
 
 // TestGenerator_parseExampleValue tests the parsing of example values.
 func TestGenerator_parseExampleValue(t *testing.T) {
@@ -1028,12 +2501,1507 @@ func TestGenerator_parseExampleValueError(t *testing.T) {
 	assert.Error(t, err, "parseExampleValue does not support type")
 }
 
-func gen(t *testing.T) *Generator {
-	g, err := NewGenerator(genConfig)
+// TestResponseContents tests that a response can
+// have a content with a schema for several media types.
+func TestResponseContents(t *testing.T) {
+	type JSONOut struct {
+		A string `json:"a"`
+	}
+	type XMLOut struct {
+		B string `xml:"b"`
+	}
+	g := gen(t)
+
+	_, err := g.AddOperation("/out", "GET", "Test", "", "application/json", nil, rt(JSONOut{}), &OperationInfo{
+		ID:         "GetOut",
+		StatusCode: 200,
+		Responses: []*OperationResponse{{
+			Code:        "202",
+			Description: "Accepted",
+			Contents: map[string]interface{}{
+				"application/json": JSONOut{},
+				"application/xml":  &XMLOut{},
+			},
+		}},
+	})
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
 	}
-	g.UseFullSchemaNames(false)
+	responses := g.API().Paths["/out"].GET.Responses
 
-	return g
+	// The default response has a single media type.
+	assert.Len(t, responses["200"].Content, 1)
+
+	content := responses["202"].Content
+	assert.Len(t, content, 2)
+	if assert.Contains(t, content, "application/json") {
+		assert.Equal(t, componentsSchemaPath+"JSONOut", content["application/json"].Schema.Reference.Ref)
+	}
+	if assert.Contains(t, content, "application/xml") {
+		assert.Equal(t, componentsSchemaPath+"XMLOut", content["application/xml"].Schema.Reference.Ref)
+	}
+}
+
+// TestStatusContents tests that the default response
+// can document additional media types with their models.
+func TestStatusContents(t *testing.T) {
+	type Out struct {
+		A string `json:"a"`
+	}
+	g := gen(t)
+
+	_, err := g.AddOperation("/out", "GET", "Test", "", "application/json", nil, rt(Out{}), &OperationInfo{
+		ID:         "GetOut",
+		StatusCode: 200,
+		StatusContents: map[string]interface{}{
+			"text/csv": "",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := g.API().Paths["/out"].GET.Responses["200"].Content
+	assert.Len(t, content, 2)
+
+	// The model of the handler stays under
+	// the media type of the response.
+	if assert.Contains(t, content, "application/json") {
+		assert.Equal(t, componentsSchemaPath+"Out", content["application/json"].Schema.Reference.Ref)
+	}
+	if assert.Contains(t, content, "text/csv") {
+		assert.Equal(t, "string", content["text/csv"].Schema.Type)
+	}
+}
+
+// TestRegisterEnum tests that the values registered
+// for an enum type are listed with their names in the
+// schema of the fields of this type.
+func TestRegisterEnum(t *testing.T) {
+	type Status int
+
+	const (
+		StatusActive Status = iota + 1
+		StatusSuspended
+		StatusDeleted
+	)
+	type T struct {
+		A Status   `json:"a"`
+		B []Status `json:"b"`
+		C *Status  `json:"c" enum:"1,2"`
+	}
+	g := gen(t)
+
+	values := []EnumValue{
+		{Value: StatusActive, Name: "StatusActive"},
+		{Value: StatusSuspended, Name: "StatusSuspended"},
+		{Value: StatusDeleted, Name: "StatusDeleted"},
+	}
+	assert.NotNil(t, g.RegisterEnum(rt(Status(0)), nil))
+	assert.NotNil(t, g.RegisterEnum(rt(Status(0)), []EnumValue{{Value: "active", Name: "StatusActive"}}))
+	assert.NotNil(t, g.RegisterEnum(rt(Status(0)), []EnumValue{{Value: StatusActive}}))
+	assert.Nil(t, g.RegisterEnum(rt(Status(0)), values))
+	assert.NotNil(t, g.RegisterEnum(rt(new(Status)), values))
+
+	sor := g.newSchemaFromType(rt(T{}), tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.Empty(t, g.Errors())
+
+	b, err := json.Marshal(g.resolveSchema(sor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m struct {
+		Properties struct {
+			A struct {
+				Enum     []int    `json:"enum"`
+				VarNames []string `json:"x-enum-varnames"`
+			} `json:"a"`
+			B struct {
+				Items struct {
+					Enum     []int    `json:"enum"`
+					VarNames []string `json:"x-enum-varnames"`
+				} `json:"items"`
+			} `json:"b"`
+			C struct {
+				Enum     []int    `json:"enum"`
+				VarNames []string `json:"x-enum-varnames"`
+			} `json:"c"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	names := []string{"StatusActive", "StatusSuspended", "StatusDeleted"}
+
+	assert.Equal(t, []int{1, 2, 3}, m.Properties.A.Enum)
+	assert.Equal(t, names, m.Properties.A.VarNames)
+	assert.Equal(t, []int{1, 2, 3}, m.Properties.B.Items.Enum)
+	assert.Equal(t, names, m.Properties.B.Items.VarNames)
+
+	// The enum tag takes precedence.
+	assert.Equal(t, []int{1, 2}, m.Properties.C.Enum)
+	assert.Empty(t, m.Properties.C.VarNames)
+}
+
+// RecA and RecB are mutually recursive types.
+type RecA struct {
+	B *RecB `json:"b"`
+}
+type RecB struct {
+	A  *RecA  `json:"a"`
+	As []RecA `json:"as"`
+}
+
+// TestSchemaFromMutuallyRecursiveTypes tests that the
+// schemas of mutually recursive types reference each
+// other instead of recursing infinitely.
+func TestSchemaFromMutuallyRecursiveTypes(t *testing.T) {
+	g := gen(t)
+
+	sor := g.newSchemaFromType(rt(RecA{}), tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.Empty(t, g.Errors())
+
+	schemas := g.API().Components.Schemas
+	assert.Len(t, schemas, 2)
+
+	a, b := schemas["RecA"], schemas["RecB"]
+	if assert.NotNil(t, a) && assert.NotNil(t, b) {
+		assert.Equal(t, componentsSchemaPath+"RecB", a.Properties["b"].Reference.Ref)
+		assert.Equal(t, componentsSchemaPath+"RecA", b.Properties["a"].Reference.Ref)
+		assert.Equal(t, componentsSchemaPath+"RecA", b.Properties["as"].Items.Reference.Ref)
+	}
+	if _, err := json.Marshal(g.API()); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestSchemaRequiredOmitEmpty tests that the omitempty
+// option of a field is considered to build the list of
+// the required properties of a schema.
+func TestSchemaRequiredOmitEmpty(t *testing.T) {
+	type T struct {
+		A string  `json:"a,omitempty" validate:"required"`
+		B *string `json:"b,omitempty" validate:"required"`
+		C string  `json:"c,omitempty"`
+		D *string `json:"d,omitempty"`
+		E string  `json:"e" validate:"required"`
+		F *string `json:"f" validate:"required"`
+		G string  `json:"g"`
+		H *string `json:"h"`
+	}
+	g := gen(t)
+
+	sor := g.newSchemaFromType(rt(T{}), tonic.MediaType())
+	assert.NotNil(t, sor)
+
+	schema := g.resolveSchema(sor)
+	if assert.NotNil(t, schema) {
+		assert.Equal(t, []string{"a", "e", "f"}, schema.Required)
+		assert.Len(t, schema.Properties, 8)
+	}
+	assert.Empty(t, g.Errors())
+}
+
+// TestMergeSpec tests that the paths and components
+// of another spec can be merged in the generated one.
+func TestMergeSpec(t *testing.T) {
+	type Shared struct {
+		ID string `json:"id"`
+	}
+	type User struct {
+		Shared
+		Name string `json:"name"`
+	}
+	type Order struct {
+		Shared
+		Amount int `json:"amount"`
+	}
+	newGen := func(path, id string, out reflect.Type, tag string) *Generator {
+		g := gen(t)
+		g.AddTag(tag, tag+" operations")
+		_, err := g.AddOperation(path, "GET", tag, "", tonic.MediaType(), nil, out, &OperationInfo{
+			ID:         id,
+			StatusCode: 200,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := g.AddOperation("/shared", "GET", tag, "", tonic.MediaType(), nil, rt(Shared{}), &OperationInfo{
+			ID:         id + "Shared",
+			StatusCode: 200,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		err = g.AddSecurityScheme("bearer", &SecurityScheme{Type: "http", Scheme: "bearer"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g
+	}
+	users := newGen("/users", "GetUsers", rt(User{}), "Users")
+	orders := newGen("/orders", "GetOrders", rt(Order{}), "Orders")
+
+	err := users.MergeSpec(orders.API(), "/orders-service/")
+	assert.Nil(t, err)
+
+	api := users.API()
+	assert.Len(t, api.Paths, 4)
+	for _, path := range []string{"/users", "/shared", "/orders-service/orders", "/orders-service/shared"} {
+		if assert.Contains(t, api.Paths, path) {
+			assert.NotNil(t, api.Paths[path].GET)
+		}
+	}
+	assert.Len(t, api.Components.Schemas, 3)
+	assert.Contains(t, api.Components.Schemas, "User")
+	assert.Contains(t, api.Components.Schemas, "Order")
+	assert.Contains(t, api.Components.Schemas, "Shared")
+	assert.Len(t, api.Components.SecuritySchemes, 1)
+	assert.Len(t, api.Tags, 2)
+
+	// Conflicting path and method.
+	other := newGen("/users", "ListUsers", rt(User{}), "Users")
+	err = users.MergeSpec(other.API(), "")
+	assert.NotNil(t, err)
+
+	// Conflicting schema name.
+	other = gen(t)
+	err = other.OverrideTypeName(rt(Order{}), "User")
+	assert.Nil(t, err)
+	_, err = other.AddOperation("/accounts", "GET", "", "", tonic.MediaType(), nil, rt(Order{}), &OperationInfo{
+		ID:         "GetAccounts",
+		StatusCode: 200,
+	})
+	assert.Nil(t, err)
+	err = users.MergeSpec(other.API(), "")
+	assert.NotNil(t, err)
+
+	// The spec is unchanged after a failed merge.
+	assert.Len(t, users.API().Paths, 4)
+	assert.NotContains(t, users.API().Paths, "/accounts")
+
+	// Duplicate IDs in the other spec.
+	err = users.MergeSpec(&OpenAPI{
+		Paths: Paths{
+			"/a": &PathItem{GET: &Operation{ID: "Dup"}},
+			"/b": &PathItem{GET: &Operation{ID: "Dup"}},
+		},
+	}, "")
+	assert.NotNil(t, err)
+	assert.NotContains(t, users.API().Paths, "/a")
+
+	// The merged operations and schemas are copies.
+	orders.API().Paths["/orders"].GET.Summary = "Changed"
+	orders.API().Components.Schemas["Order"].Schema.Description = "Changed"
+	assert.Empty(t, users.API().Paths["/orders-service/orders"].GET.Summary)
+	assert.Empty(t, users.API().Components.Schemas["Order"].Schema.Description)
+
+	// The path-level fields of an existing path are
+	// kept, and its parameters only apply to the
+	// merged operations.
+	id := &ParameterOrRef{Parameter: &Parameter{
+		Name:   "id",
+		In:     "query",
+		Schema: &SchemaOrRef{Schema: &Schema{Type: "string"}},
+	}}
+	err = users.MergeSpec(&OpenAPI{
+		Paths: Paths{
+			"/users": &PathItem{
+				Summary:     "Users",
+				Description: "Users of the service",
+				Parameters:  []*ParameterOrRef{id},
+				PATCH:       &Operation{ID: "PatchUsers"},
+			},
+		},
+	}, "")
+	assert.Nil(t, err)
+
+	item := users.API().Paths["/users"]
+	assert.Equal(t, "Users", item.Summary)
+	assert.Equal(t, "Users of the service", item.Description)
+	assert.Empty(t, item.Parameters)
+	assert.Empty(t, item.GET.Parameters)
+	if assert.NotNil(t, item.PATCH) && assert.Len(t, item.PATCH.Parameters, 1) {
+		assert.Equal(t, "id", item.PATCH.Parameters[0].Name)
+	}
+}
+
+// dedupeAddress is an anonymous struct type, whose
+// schema is always inlined in the specification.
+type dedupeAddress = struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+}
+
+// TestDedupeSchemas tests that the identical schemas
+// of anonymous structs are moved to a shared component.
+func TestDedupeSchemas(t *testing.T) {
+	type Out struct {
+		Billing  dedupeAddress   `json:"billing"`
+		Shipping *dedupeAddress  `json:"shipping"`
+		History  []dedupeAddress `json:"history"`
+		Other    struct {
+			Street string `json:"street"`
+		} `json:"other"`
+	}
+	for _, dedupe := range []bool{false, true} {
+		g := gen(t)
+		g.SetDedupeSchemas(dedupe)
+
+		_, err := g.AddOperation("/out", "GET", "Test", "", tonic.MediaType(), nil, rt(Out{}), &OperationInfo{
+			ID:         "GetOut",
+			StatusCode: 200,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		api := g.API()
+		props := api.Components.Schemas["Out"].Properties
+
+		if !dedupe {
+			assert.Len(t, api.Components.Schemas, 1)
+			assert.NotNil(t, props["billing"].Schema)
+			assert.NotNil(t, props["shipping"].Schema)
+			assert.NotNil(t, props["history"].Items.Schema)
+			continue
+		}
+		assert.Len(t, api.Components.Schemas, 2)
+
+		var name string
+		for n := range api.Components.Schemas {
+			if n != "Out" {
+				name = n
+			}
+		}
+		assert.True(t, strings.HasPrefix(name, "Inline."))
+
+		ref := componentsSchemaPath + name
+		for _, sor := range []*SchemaOrRef{
+			props["billing"],
+			props["shipping"],
+			props["history"].Items,
+		} {
+			if assert.NotNil(t, sor.Reference) {
+				assert.Equal(t, ref, sor.Reference.Ref)
+			}
+			assert.Nil(t, sor.Schema)
+		}
+		// The schema of a different struct is unique
+		// and stays inlined.
+		assert.Nil(t, props["other"].Reference)
+		assert.NotNil(t, props["other"].Schema)
+
+		shared := api.Components.Schemas[name].Schema
+		if assert.NotNil(t, shared) {
+			assert.Equal(t, "object", shared.Type)
+			assert.Len(t, shared.Properties, 2)
+		}
+		// The name of the shared component is stable.
+		api = g.API()
+		assert.Len(t, api.Components.Schemas, 2)
+		assert.Contains(t, api.Components.Schemas, name)
+	}
+}
+
+// TestMaxSchemaDepth tests that the schemas of anonymous
+// structs nested deeper than the maximum depth are moved
+// into the components and replaced with references.
+func TestMaxSchemaDepth(t *testing.T) {
+	type Nested struct {
+		A struct {
+			B struct {
+				C struct {
+					D struct {
+						E struct {
+							V string `json:"v"`
+						} `json:"e"`
+					} `json:"d"`
+				} `json:"c"`
+			} `json:"b"`
+		} `json:"a"`
+	}
+	for _, depth := range []int{0, 2} {
+		g := gen(t)
+		g.SetMaxSchemaDepth(depth)
+
+		_, err := g.AddOperation("/nested", "GET", "Test", "", tonic.MediaType(), nil, rt(Nested{}), &OperationInfo{
+			ID:         "GetNested",
+			StatusCode: 200,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, g.Errors())
+
+		schemas := g.API().Components.Schemas
+		a := schemas["Nested"].Properties["a"]
+		b := a.Properties["b"]
+
+		if depth == 0 {
+			// Default to unlimited inlining.
+			assert.Len(t, schemas, 1)
+			e := b.Properties["c"].Properties["d"].Properties["e"]
+			if assert.NotNil(t, e.Schema) {
+				assert.Contains(t, e.Properties, "v")
+			}
+			continue
+		}
+		assert.Len(t, schemas, 2)
+		assert.NotNil(t, a.Schema)
+		assert.NotNil(t, b.Schema)
+
+		c := b.Properties["c"]
+		if !assert.NotNil(t, c.Reference) {
+			continue
+		}
+		assert.Nil(t, c.Schema)
+		assert.True(t, strings.HasPrefix(c.Ref, componentsSchemaPath+"Inline."))
+
+		// The depth of the nested schemas starts
+		// again from the hoisted component.
+		hoisted := g.resolveSchema(c)
+		if assert.NotNil(t, hoisted) {
+			e := hoisted.Properties["d"].Properties["e"]
+			if assert.NotNil(t, e.Schema) {
+				assert.Contains(t, e.Properties, "v")
+			}
+		}
+	}
+}
+
+// BenchmarkDedupeSchemas measures the size of a
+// specification in which an anonymous struct is
+// reused ten times, with and without deduplication.
+func BenchmarkDedupeSchemas(b *testing.B) {
+	fields := make([]reflect.StructField, 10)
+	for i := range fields {
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Address%d", i),
+			Type: rt(dedupeAddress{}),
+			Tag:  reflect.StructTag(fmt.Sprintf(`json:"address%d"`, i)),
+		}
+	}
+	out := reflect.StructOf(fields)
+
+	for _, dedupe := range []bool{false, true} {
+		b.Run(fmt.Sprintf("dedupe=%t", dedupe), func(b *testing.B) {
+			var size int
+			for i := 0; i < b.N; i++ {
+				g, err := NewGenerator(genConfig)
+				if err != nil {
+					b.Fatal(err)
+				}
+				g.SetDedupeSchemas(dedupe)
+
+				_, err = g.AddOperation("/out", "GET", "Test", "", tonic.MediaType(), nil, out, &OperationInfo{
+					ID:         "GetOut",
+					StatusCode: 200,
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+				d, err := json.Marshal(g.API())
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = len(d)
+			}
+			b.ReportMetric(float64(size), "bytes/spec")
+		})
+	}
+}
+
+func gen(t *testing.T) *Generator {
+	g, err := NewGenerator(genConfig)
+	if err != nil {
+		t.Error(err)
+	}
+	g.UseFullSchemaNames(false)
+
+	return g
+}
+
+// TestSetDefaultResponse tests that a default response
+// is added to the operations that don't define its code.
+func TestSetDefaultResponse(t *testing.T) {
+	type Error struct {
+		Message string `json:"message"`
+	}
+	g := gen(t)
+
+	for _, path := range []string{"/a", "/b"} {
+		_, err := g.AddOperation(path, "GET", "", "", tonic.MediaType(), nil, nil, &OperationInfo{
+			ID:         "Get" + path[1:],
+			StatusCode: 200,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := g.AddOperation("/c", "GET", "", "", tonic.MediaType(), nil, nil, &OperationInfo{
+		ID:         "Getc",
+		StatusCode: 200,
+		Responses: []*OperationResponse{{
+			Code:        "500",
+			Description: "Own error",
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = g.SetDefaultResponse("600", "", Error{})
+	assert.NotNil(t, err)
+
+	err = g.SetDefaultResponse("500", "Server error", Error{})
+	assert.Nil(t, err)
+
+	api := g.API()
+	for _, path := range []string{"/a", "/b"} {
+		resp := api.Paths[path].GET.Responses["500"]
+		if assert.NotNil(t, resp) {
+			assert.Equal(t, "Server error", resp.Description)
+			mt := resp.Content[tonic.MediaType()]
+			if assert.NotNil(t, mt) {
+				assert.Equal(t, componentsSchemaPath+"Error", mt.Schema.Ref)
+			}
+		}
+		assert.NotNil(t, api.Paths[path].GET.Responses["200"])
+	}
+	assert.Equal(t, "Own error", api.Paths["/c"].GET.Responses["500"].Description)
+
+	// Operations added afterward get the default response.
+	_, err = g.AddOperation("/d", "GET", "", "", tonic.MediaType(), nil, nil, &OperationInfo{
+		ID:         "Getd",
+		StatusCode: 200,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotNil(t, g.API().Paths["/d"].GET.Responses["500"])
+	assert.Empty(t, g.Errors())
+
+	// The default responses are added once to each
+	// operation, retrieving the specification does
+	// not generate them again.
+	g.SetRequireResponseDescriptions(true)
+
+	err = g.SetDefaultResponse("503", "", nil)
+	assert.Nil(t, err)
+	assert.Len(t, g.Errors(), 4)
+
+	for i := 0; i < 2; i++ {
+		for _, path := range []string{"/a", "/b", "/c", "/d"} {
+			assert.NotNil(t, g.API().Paths[path].GET.Responses["503"])
+		}
+	}
+	assert.Len(t, g.Errors(), 4)
+}
+
+// TestAddRangeResponse tests that the responses of the
+// ranges of codes are added to the operations that don't
+// declare the same ranges.
+func TestAddRangeResponse(t *testing.T) {
+	type Error struct {
+		Message string `json:"message"`
+	}
+	g := gen(t)
+
+	_, err := g.AddOperation("/a", "GET", "", "", tonic.MediaType(), nil, nil, &OperationInfo{
+		ID:         "Geta",
+		StatusCode: 200,
+		Responses: []*OperationResponse{{
+			Code:        "404",
+			Description: "Not found",
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = g.AddOperation("/b", "GET", "", "", tonic.MediaType(), nil, nil, &OperationInfo{
+		ID:         "Getb",
+		StatusCode: 200,
+		Responses: []*OperationResponse{{
+			Code:        "5XX",
+			Description: "Own error",
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, code := range []string{"500", "6XX", "4xx", "default"} {
+		assert.NotNil(t, g.AddRangeResponse(code, "", Error{}), code)
+	}
+	assert.Nil(t, g.AddRangeResponse("4XX", "Client error", Error{}))
+	assert.Nil(t, g.AddRangeResponse("5XX", "Server error", Error{}))
+
+	responses := g.API().Paths["/a"].GET.Responses
+	for code, desc := range map[string]string{
+		"4XX": "Client error",
+		"5XX": "Server error",
+	} {
+		resp := responses[code]
+		if assert.NotNil(t, resp, code) {
+			assert.Equal(t, desc, resp.Description)
+			mt := resp.Content[tonic.MediaType()]
+			if assert.NotNil(t, mt) {
+				assert.Equal(t, componentsSchemaPath+"Error", mt.Schema.Ref)
+			}
+		}
+	}
+	assert.Equal(t, "Not found", responses["404"].Description)
+
+	responses = g.API().Paths["/b"].GET.Responses
+	assert.Equal(t, "Client error", responses["4XX"].Description)
+	assert.Equal(t, "Own error", responses["5XX"].Description)
+}
+
+// TestClone tests that the changes made to a clone
+// of a generator don't affect the original one.
+func TestClone(t *testing.T) {
+	type User struct {
+		Name string `json:"name" example:"john"`
+	}
+	g := gen(t)
+	g.AddTag("Users", "Users operations")
+
+	_, err := g.AddOperation("/users", "GET", "Users", "", tonic.MediaType(), nil, rt(User{}), &OperationInfo{
+		ID:         "GetUsers",
+		StatusCode: 200,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := g.Clone()
+
+	_, err = c.AddOperation("/internal", "GET", "", "", tonic.MediaType(), nil, nil, &OperationInfo{
+		ID:         "GetInternal",
+		StatusCode: 200,
+	})
+	assert.Nil(t, err)
+	c.AddTag("Users", "Internal users operations")
+	c.API().Components.Schemas["User"].Description = "An internal user"
+	err = c.OverrideDataType(rt(W{}), "string", "wallet")
+	assert.Nil(t, err)
+
+	// The operation ID is still registered in the clone.
+	_, err = c.AddOperation("/users2", "GET", "", "", tonic.MediaType(), nil, nil, &OperationInfo{
+		ID:         "GetUsers",
+		StatusCode: 200,
+	})
+	assert.NotNil(t, err)
+
+	api := g.API()
+	assert.Len(t, api.Paths, 1)
+	assert.NotContains(t, api.Paths, "/internal")
+	assert.Equal(t, "Users operations", api.Tags[0].Description)
+	assert.Empty(t, api.Components.Schemas["User"].Description)
+	assert.Nil(t, g.overrideSchema(rt(W{})))
+
+	capi := c.API()
+	assert.Len(t, capi.Paths, 2)
+	assert.Equal(t, "john", capi.Components.Schemas["User"].Properties["name"].Example)
+
+	// The references to the components are preserved.
+	resp := capi.Paths["/users"].GET.Responses["200"]
+	assert.Equal(t, componentsSchemaPath+"User", resp.Content[tonic.MediaType()].Schema.Ref)
+
+	// The original generator can still be used.
+	_, err = g.AddOperation("/internal", "POST", "", "", tonic.MediaType(), nil, nil, &OperationInfo{
+		ID:         "PostInternal",
+		StatusCode: 200,
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, c.API().Paths["/internal"].POST)
+}
+
+// TestExportTypeScript tests that the TypeScript
+// declarations of the component schemas match the
+// golden file.
+func TestExportTypeScript(t *testing.T) {
+	g := gen(t)
+
+	sor := g.newSchemaFromType(rt(new(X)), tonic.MediaType())
+	assert.NotNil(t, sor)
+
+	var b bytes.Buffer
+	err := g.ExportTypeScript(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// see testdata/typescript/X.d.ts.
+	expected, err := ioutil.ReadFile("../testdata/typescript/X.d.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(expected), b.String())
+
+	// Enums are described with unions of literals.
+	type Pet struct {
+		Kind  string   `json:"kind" enum:"cat,dog" description:"The kind of pet"`
+		Tags  []string `json:"tags" enum:"small,big"`
+		Owner *string  `json:"owner-name"`
+	}
+	g = gen(t)
+	g.newSchemaFromType(rt(Pet{}), tonic.MediaType())
+
+	b.Reset()
+	err = g.ExportTypeScript(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `export interface Pet {
+  /**
+   * The kind of pet
+   */
+  kind?: "cat" | "dog";
+  "owner-name"?: string | null;
+  tags?: ("small" | "big")[];
+}
+`, b.String())
+}
+
+// TestExportPostman tests that the operations are
+// exported as the requests of a Postman collection.
+func TestExportPostman(t *testing.T) {
+	type GetIn struct {
+		ID     string `path:"id"`
+		Fields string `query:"fields" default:"all"`
+	}
+	type CreateIn struct {
+		Name string   `json:"name" example:"john"`
+		Age  int      `json:"age"`
+		Tags []string `json:"tags"`
+	}
+	g := gen(t)
+	g.SetInfo(&Info{Title: "Users API"})
+	g.SetServers([]*Server{{URL: "https://example.com/api/"}})
+	g.AddTag("Users", "Users operations")
+
+	_, err := g.AddOperation("/users/:id", "GET", "Users", "", tonic.MediaType(), rt(GetIn{}), nil, &OperationInfo{
+		ID:         "GetUser",
+		StatusCode: 200,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = g.AddOperation("/users", "POST", "Users", tonic.MediaType(), tonic.MediaType(), rt(CreateIn{}), nil, &OperationInfo{
+		ID:         "CreateUser",
+		Summary:    "Create a user",
+		StatusCode: 201,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := g.ExportPostman(&b); err != nil {
+		t.Fatal(err)
+	}
+	var c postmanCollection
+	if err := json.Unmarshal(b.Bytes(), &c); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Users API", c.Info.Name)
+	assert.Equal(t, postmanSchema, c.Info.Schema)
+	assert.Equal(t, []*postmanVariable{{Key: "baseUrl", Value: "https://example.com/api"}}, c.Variable)
+
+	if !assert.Len(t, c.Item, 1) {
+		return
+	}
+	folder := c.Item[0]
+	assert.Equal(t, "Users", folder.Name)
+	if !assert.Len(t, folder.Item, 2) {
+		return
+	}
+	create, get := folder.Item[0].Request, folder.Item[1].Request
+
+	assert.Equal(t, "Create a user", folder.Item[0].Name)
+	assert.Equal(t, "POST", create.Method)
+	assert.Equal(t, "{{baseUrl}}/users", create.URL.Raw)
+	if assert.NotNil(t, create.Body) {
+		assert.Equal(t, "raw", create.Body.Mode)
+		assert.JSONEq(t, `{"name":"john","age":0,"tags":["string"]}`, create.Body.Raw)
+	}
+	assert.Equal(t, []*postmanVariable{{Key: "Content-Type", Value: tonic.MediaType()}}, create.Header)
+
+	assert.Equal(t, "GetUser", folder.Item[1].Name)
+	assert.Equal(t, "GET", get.Method)
+	assert.Equal(t, "{{baseUrl}}/users/:id?fields=all", get.URL.Raw)
+	assert.Equal(t, []string{"users", ":id"}, get.URL.Path)
+	assert.Equal(t, []*postmanVariable{{Key: "id", Value: ""}}, get.URL.Variable)
+	assert.Nil(t, get.Body)
+}
+
+// TestRequireResponseDescriptions tests that a response
+// without description is reported only if descriptions
+// are required.
+func TestRequireResponseDescriptions(t *testing.T) {
+	for _, require := range []bool{false, true} {
+		g := gen(t)
+		g.SetRequireResponseDescriptions(require)
+
+		op, err := g.AddOperation("/a", "GET", "", "", tonic.MediaType(), nil, nil, &OperationInfo{
+			ID:         "GetA",
+			StatusCode: 200,
+			Responses: []*OperationResponse{{
+				Code:        "404",
+				Description: "User not found",
+			}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "OK", op.Responses["200"].Description)
+		assert.Equal(t, "User not found", op.Responses["404"].Description)
+
+		if require {
+			if assert.Len(t, g.Errors(), 1) {
+				assert.Contains(t, g.Errors()[0].Error(), "code 200")
+			}
+		} else {
+			assert.Empty(t, g.Errors())
+		}
+	}
+}
+
+func TestValidateSpec(t *testing.T) {
+	type T struct {
+		ID   string `path:"id"`
+		Name string `json:"name"`
+	}
+	g := gen(t)
+
+	for method, id := range map[string]string{"GET": "GetT", "PUT": "PutT"} {
+		_, err := g.AddOperation("/t/{id}", method, "", "", tonic.MediaType(), rt(&T{}), rt(&T{}), &OperationInfo{
+			ID:         id,
+			StatusCode: 200,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.Nil(t, g.ValidateSpec())
+
+	schemas := g.api.Components.Schemas
+	schemas["Broken"] = &SchemaOrRef{Reference: &Reference{Ref: "#/components/schemas/Missing"}}
+	schemas["Partial"] = &SchemaOrRef{Schema: &Schema{
+		Type:       "object",
+		Properties: map[string]*SchemaOrRef{"a": {Schema: &Schema{Type: "string"}}},
+		Required:   []string{"a", "b"},
+	}}
+	item := g.api.Paths["/t/{id}"]
+	item.PUT.ID = "GetT"
+	item.GET.Parameters = append(item.GET.Parameters, &ParameterOrRef{Parameter: &Parameter{
+		Name: "extra", In: "path", Required: true,
+	}})
+	item.PUT.Parameters = nil
+
+	// References of the path item, the request bodies,
+	// and the headers and examples of the responses.
+	item.Parameters = append(item.Parameters, &ParameterOrRef{Reference: &Reference{
+		Ref: "#/components/parameters/Missing",
+	}})
+	g.api.Components.RequestBodies = map[string]*RequestBody{
+		"T": item.PUT.RequestBody,
+	}
+	item.PUT.RequestBody = &RequestBody{Ref: "#/components/requestBodies/T"}
+	item.GET.RequestBody = &RequestBody{Ref: "#/components/requestBodies/Missing"}
+
+	resp := item.GET.Responses["200"]
+	resp.Headers["X-Missing"] = &HeaderOrRef{Reference: &Reference{
+		Ref: "#/components/headers/Missing",
+	}}
+	resp.Content[tonic.MediaType()].Examples = map[string]*ExampleOrRef{
+		"missing": {Reference: &Reference{Ref: "#/components/examples/Missing"}},
+	}
+	var msgs []string
+	for _, err := range g.ValidateSpec() {
+		msgs = append(msgs, err.Error())
+	}
+	assert.ElementsMatch(t, []string{
+		"reference #/components/parameters/Missing does not resolve to a component",
+		"reference #/components/requestBodies/Missing does not resolve to a component",
+		"reference #/components/headers/Missing does not resolve to a component",
+		"reference #/components/examples/Missing does not resolve to a component",
+		"reference #/components/schemas/Missing does not resolve to a component",
+		"required property b is not defined in the schema",
+		"path parameter extra of GET /t/{id} is not used in the path",
+		"operation ID GetT of PUT /t/{id} is already used by GET /t/{id}",
+		"path parameter id of PUT /t/{id} is not declared by the operation",
+	}, msgs)
+}
+
+// TestJSONSchemaFor tests that the standalone JSON
+// Schema of a type defines the component schemas it
+// references.
+func TestJSONSchemaFor(t *testing.T) {
+	g := gen(t)
+
+	b, err := g.JSONSchemaFor(rt(X{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s struct {
+		Schema     string                     `json:"$schema"`
+		Type       string                     `json:"type"`
+		Properties map[string]json.RawMessage `json:"properties"`
+		Defs       map[string]json.RawMessage `json:"$defs"`
+	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", s.Schema)
+	assert.Equal(t, "object", s.Type)
+	assert.JSONEq(t, `{"$ref":"#/$defs/Y"}`, string(s.Properties["G"]))
+	assert.JSONEq(t, `{"type":["integer","null"],"format":"int32"}`, string(s.Properties["B"]))
+	assert.Contains(t, s.Defs, "Y")
+	assert.Contains(t, s.Defs, "XXX")
+	assert.NotContains(t, string(b), "#/components/")
+
+	// The references of the components of the
+	// specification are left unchanged.
+	assert.Equal(t, componentsSchemaPath+"Y", g.API().Components.Schemas["XXX"].Properties["G"].Ref)
+
+	b, err = g.JSONSchemaFor(rt(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"string"}`, string(b))
+
+	_, err = g.JSONSchemaFor(rt(make(chan int)))
+	assert.NotNil(t, err)
+}
+
+// TestInputMediaType tests that the media type of
+// the request body can be set by the operation.
+func TestInputMediaType(t *testing.T) {
+	type In struct {
+		ID   string `path:"id"`
+		Name string `json:"name"`
+	}
+	g := gen(t)
+
+	op, err := g.AddOperation("/a/{id}", "POST", "", tonic.MediaType(), tonic.MediaType(), rt(In{}), nil, &OperationInfo{
+		ID:         "PostA",
+		StatusCode: 200,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, op.RequestBody.Content, "application/json")
+
+	op, err = g.AddOperation("/b/{id}", "POST", "", "application/json", tonic.MediaType(), rt(In{}), nil, &OperationInfo{
+		ID:             "PostB",
+		StatusCode:     200,
+		InputMediaType: "multipart/form-data",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, op.RequestBody.Content, 1) {
+		mt := op.RequestBody.Content["multipart/form-data"]
+		if assert.NotNil(t, mt) {
+			assert.Contains(t, mt.Schema.Properties, "Name")
+		}
+	}
+	assert.Len(t, op.Parameters, 1)
+	assert.Empty(t, g.Errors())
+}
+
+// TestExtensions tests that the specification extensions
+// are marshaled along with the fields of the operations
+// and the schemas.
+func TestExtensions(t *testing.T) {
+	type Account struct {
+		ID string `json:"id"`
+	}
+	g := gen(t)
+
+	err := g.AddSchemaExtension(rt(""), "x-internal", true)
+	assert.NotNil(t, err)
+	err = g.AddSchemaExtension(rt(&Account{}), "internal", true)
+	assert.NotNil(t, err)
+	err = g.AddSchemaExtension(rt(&Account{}), "x-internal", true)
+	assert.Nil(t, err)
+
+	_, err = g.AddOperation("/a", "GET", "", "", tonic.MediaType(), nil, rt(Account{}), &OperationInfo{
+		ID:         "GetA",
+		StatusCode: 200,
+		Extensions: map[string]interface{}{"x-": 1},
+	})
+	assert.NotNil(t, err)
+
+	op, err := g.AddOperation("/b", "GET", "", "", tonic.MediaType(), nil, rt(Account{}), &OperationInfo{
+		ID:         "GetB",
+		StatusCode: 200,
+		Extensions: map[string]interface{}{"x-ratelimit": 60},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(b), `"x-ratelimit":60`)
+
+	y, err := yaml.Marshal(op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(y), "x-ratelimit: 60\n")
+
+	for _, v := range []string{"3.0.3", "3.1.0"} {
+		if err := g.SetOpenAPIVersion(v); err != nil {
+			t.Fatal(err)
+		}
+		s := g.API().Components.Schemas["Account"]
+
+		b, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Contains(t, string(b), `,"x-internal":true}`)
+
+		y, err := yaml.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Contains(t, string(y), "x-internal: true\n")
+	}
+	// A schema with no other fields.
+	b, err = json.Marshal(&Schema{Extensions: map[string]interface{}{"x-a": 1, "x-b": 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `{"x-a":1,"x-b":2}`, string(b))
+}
+
+// TestDeterministicOutput tests that the marshaled
+// specification is the same for generators of the
+// same operations, regardless of the iteration order
+// of the maps of the components.
+func TestDeterministicOutput(t *testing.T) {
+	build := func() *Generator {
+		g := gen(t)
+		for i, typ := range []reflect.Type{rt(X{}), rt(Y{}), rt(W{}), rt(Q{}), rt(V{})} {
+			_, err := g.AddOperation(fmt.Sprintf("/%d", i), "GET", "", "", tonic.MediaType(), nil, typ, &OperationInfo{
+				ID:         fmt.Sprintf("Get%d", i),
+				StatusCode: 200,
+				Responses: []*OperationResponse{{
+					Code:  "400",
+					Model: W{},
+				}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		g.SetSecuritySchemes(map[string]*SecuritySchemeOrRef{
+			"basic":  {SecurityScheme: &SecurityScheme{Type: "http", Scheme: "basic"}},
+			"bearer": {SecurityScheme: &SecurityScheme{Type: "http", Scheme: "bearer"}},
+			"apiKey": {SecurityScheme: &SecurityScheme{Type: "apiKey", Name: "X-Key", In: "header"}},
+		})
+		return g
+	}
+	var outputs [][]byte
+	for i := 0; i < 5; i++ {
+		api := build().API()
+
+		b, err := json.Marshal(api)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, err := yaml.Marshal(api)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, append(b, y...))
+	}
+	for _, o := range outputs[1:] {
+		assert.Equal(t, string(outputs[0]), string(o))
+	}
+}
+
+// TestRequiredConditions tests that the conditionally
+// required fields are described by an extension of the
+// schema of their parent in OpenAPI 3.0, and by the
+// dependentRequired keyword in OpenAPI 3.1.
+func TestRequiredConditions(t *testing.T) {
+	type Contact struct {
+		Kind  string `json:"kind"`
+		Email string `json:"email" validate:"required_if=Kind email"`
+		Phone string `json:"phone" validate:"required_with=Email Fax"`
+		Fax   string `json:"fax" validate:"required_without=Phone"`
+		Note  string `json:"note" validate:"required_if=Kind"`
+	}
+	g := gen(t)
+
+	sor := g.newSchemaFromType(rt(Contact{}), tonic.MediaType())
+	assert.NotNil(t, sor)
+	if assert.Len(t, g.Errors(), 1) {
+		assert.Contains(t, g.Errors()[0].Error(), "invalid required_if validator parameters")
+	}
+	s := g.resolveSchema(sor)
+	assert.Empty(t, s.Required)
+	assert.Equal(t, []*RequiredCondition{
+		{Property: "email", Validator: "required_if", Fields: []string{"kind"}, Values: []string{"email"}},
+		{Property: "phone", Validator: "required_with", Fields: []string{"email", "fax"}},
+		{Property: "fax", Validator: "required_without", Fields: []string{"phone"}},
+	}, s.RequiredConditions)
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(b), `"x-required-conditions":[`+
+		`{"property":"email","validator":"required_if","fields":["kind"],"values":["email"]},`+
+		`{"property":"phone","validator":"required_with","fields":["email","fax"]},`+
+		`{"property":"fax","validator":"required_without","fields":["phone"]}]`)
+	assert.NotContains(t, string(b), "dependentRequired")
+
+	err = g.SetOpenAPIVersion("3.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s = g.API().Components.Schemas["Contact"].Schema
+
+	b, err = json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(b), `"dependentRequired":{"email":["phone"],"fax":["phone"]}`)
+	assert.Contains(t, string(b), `"x-required-conditions":[`+
+		`{"property":"email","validator":"required_if","fields":["kind"],"values":["email"]},`+
+		`{"property":"fax","validator":"required_without","fields":["phone"]}]`)
+
+	y, err := yaml.Marshal(g.API().Components.Schemas["Contact"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, string(y), "dependentRequired:\n  email:\n  - phone\n")
+	assert.Contains(t, string(y), "x-required-conditions:\n- property: email\n")
+}
+
+// TestSchemaNames tests the accessors of
+// the component schemas.
+func TestSchemaNames(t *testing.T) {
+	g := gen(t)
+	assert.Empty(t, g.SchemaNames())
+
+	sor := g.newSchemaFromType(rt(X{}), tonic.MediaType())
+	assert.NotNil(t, sor)
+
+	names := g.SchemaNames()
+	assert.Contains(t, names, "XXX")
+	assert.Contains(t, names, "Y")
+	assert.True(t, sort.StringsAreSorted(names))
+
+	s, ok := g.SchemaByName("Y")
+	if assert.True(t, ok) {
+		assert.Equal(t, "object", s.Type)
+		assert.Contains(t, s.Properties, "H")
+	}
+	_, ok = g.SchemaByName("Unknown")
+	assert.False(t, ok)
+}
+
+// TestAutoResolveOperationIDs tests that the operations
+// with a duplicate ID are renamed if the generator is
+// configured to resolve the collisions.
+func TestAutoResolveOperationIDs(t *testing.T) {
+	for _, resolve := range []bool{false, true} {
+		g := gen(t)
+		g.SetAutoResolveOperationIDs(resolve)
+
+		var ids []string
+		for _, path := range []string{"/a", "/b", "/c"} {
+			op, err := g.AddOperation(path, "POST", "", "", tonic.MediaType(), nil, nil, &OperationInfo{
+				ID:         "CreateTest",
+				StatusCode: 201,
+			})
+			if !resolve && path != "/a" {
+				assert.NotNil(t, err)
+				continue
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, op.ID)
+		}
+		if resolve {
+			assert.Equal(t, []string{"CreateTest", "CreateTest_2", "CreateTest_3"}, ids)
+			if assert.Len(t, g.Warnings(), 2) {
+				assert.Equal(t, "ID CreateTest of operation POST /b is already used by another operation, renamed to CreateTest_2", g.Warnings()[0].Error())
+			}
+			assert.Equal(t, "CreateTest_3", g.API().Paths["/c"].POST.ID)
+		} else {
+			assert.Equal(t, []string{"CreateTest"}, ids)
+			assert.Empty(t, g.Warnings())
+		}
+		assert.Empty(t, g.Errors())
+	}
+}
+
+type Tree struct {
+	Value    string  `json:"value"`
+	Children []*Tree `json:"children"`
+}
+
+// TestSelfReferentialSlice tests that the schema of
+// a type with a slice of itself references its own
+// component instead of being recursively inlined.
+func TestSelfReferentialSlice(t *testing.T) {
+	g := gen(t)
+
+	sor := g.newSchemaFromType(rt(&Tree{}), tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.Empty(t, g.Errors())
+	assert.Equal(t, componentsSchemaPath+"Tree", sor.Ref)
+
+	schemas := g.API().Components.Schemas
+	assert.Len(t, schemas, 1)
+
+	tree, ok := schemas["Tree"]
+	if !assert.True(t, ok) {
+		return
+	}
+	children := tree.Properties["children"]
+	if assert.NotNil(t, children) && assert.NotNil(t, children.Schema) {
+		assert.Equal(t, "array", children.Type)
+		if assert.NotNil(t, children.Items) {
+			assert.Nil(t, children.Items.Schema)
+			assert.Equal(t, componentsSchemaPath+"Tree", children.Items.Ref)
+		}
+	}
+}
+
+type (
+	Pet       struct{ Name string }
+	PetStatus struct{ Deleted bool }
+	petInput  struct {
+		ID string `path:"id"`
+	}
+)
+
+// TestRemoveOperation tests that an operation can be
+// removed with the components that are no longer used.
+func TestRemoveOperation(t *testing.T) {
+	g := gen(t)
+
+	for _, op := range []struct {
+		method string
+		out    reflect.Type
+	}{
+		{"GET", rt(Pet{})},
+		{"DELETE", rt(PetStatus{})},
+	} {
+		_, err := g.AddOperation("/pets/:id", op.method, "pets", "", "", rt(petInput{}), op.out, &OperationInfo{
+			ID:         op.method + "Pet",
+			StatusCode: 200,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.NotNil(t, g.RemoveOperation("/pets/:id", "PUT"))
+	assert.NotNil(t, g.RemoveOperation("/owners", "GET"))
+
+	assert.Nil(t, g.RemoveOperation("/pets/:id", "delete"))
+
+	item := g.API().Paths["/pets/{id}"]
+	if assert.NotNil(t, item) {
+		assert.NotNil(t, item.GET)
+		assert.Nil(t, item.DELETE)
+	}
+	assert.Contains(t, g.API().Components.Schemas, "PetStatus")
+	assert.Equal(t, []string{"PetStatus"}, g.RemoveUnusedSchemas())
+	assert.Equal(t, []string{"Pet"}, g.SchemaNames())
+
+	// The ID and the schema of the removed
+	// operation can be used again.
+	_, err := g.AddOperation("/pets/:id", "DELETE", "pets", "", "", rt(petInput{}), rt(PetStatus{}), &OperationInfo{
+		ID:         "DELETEPet",
+		StatusCode: 200,
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, g.API().Components.Schemas, "PetStatus")
+
+	assert.Nil(t, g.RemoveOperation("/pets/{id}", "GET"))
+	assert.Nil(t, g.RemoveOperation("/pets/{id}", "DELETE"))
+	assert.NotContains(t, g.API().Paths, "/pets/{id}")
+	assert.Equal(t, []string{"Pet", "PetStatus"}, g.RemoveUnusedSchemas())
+	assert.Empty(t, g.SchemaNames())
+}
+
+// TestStats tests the metrics of a small specification.
+func TestStats(t *testing.T) {
+	g := gen(t)
+
+	for _, op := range []struct {
+		path, method string
+		out          reflect.Type
+		info         *OperationInfo
+	}{
+		{"/pets/:id", "GET", rt(Pet{}), &OperationInfo{
+			ID:          "GetPet",
+			Description: "Get a pet",
+			Responses: []*OperationResponse{{
+				Code:        "404",
+				Description: "Not found",
+			}},
+		}},
+		{"/pets/:id", "DELETE", rt(PetStatus{}), &OperationInfo{
+			ID: "DeletePet",
+		}},
+		{"/pets", "POST", nil, &OperationInfo{
+			ID: "CreatePet",
+			Responses: []*OperationResponse{{
+				Code:        "4XX",
+				Description: "Client error",
+			}},
+		}},
+	} {
+		op.info.StatusCode = 200
+
+		_, err := g.AddOperation(op.path, op.method, "pets", "", "", nil, op.out, op.info)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.Equal(t, SpecStats{
+		Paths:                        2,
+		Operations:                   3,
+		Schemas:                      2,
+		OperationsWithoutDescription: 2,
+		OperationsWithout4xx:         1,
+		MissingDescriptions:          []string{"CreatePet", "DeletePet"},
+	}, g.Stats())
+
+	// The operations without ID are identified
+	// by their method and path.
+	err := g.MergeSpec(&OpenAPI{
+		Paths: Paths{
+			"/health": &PathItem{GET: &Operation{}},
+		},
+	}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"CreatePet", "DeletePet", "GET /health"}, g.Stats().MissingDescriptions)
+}
+
+// TestSchemaNamePrefix tests that the prefix and the
+// suffix are added to the names of all the component
+// schemas and to their references.
+func TestSchemaNamePrefix(t *testing.T) {
+	type In struct {
+		Name  string `json:"name"`
+		Owner *W     `json:"owner"`
+	}
+	g := gen(t)
+	g.UseFullSchemaNames(true)
+	g.SetSchemaNamePrefix("App")
+	g.SetSchemaNameSuffix("V1")
+
+	_, err := g.AddOperation("/x", "POST", "", tonic.MediaType(), tonic.MediaType(), rt(In{}), rt(X{}), &OperationInfo{
+		ID:         "createX",
+		StatusCode: 201,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := g.API()
+	names := g.SchemaNames()
+	assert.Contains(t, names, "AppCreateXInputV1")
+	assert.Contains(t, names, "AppXXXV1")
+	assert.Contains(t, names, "AppOpenapiYV1")
+	for _, name := range names {
+		assert.True(t, strings.HasPrefix(name, "App") && strings.HasSuffix(name, "V1"), name)
+	}
+	var refs int
+	walkSchemaRefs(api, func(sor *SchemaOrRef, _ bool) bool {
+		if sor.Reference != nil {
+			refs++
+			name := strings.TrimPrefix(sor.Ref, componentsSchemaPath)
+			assert.Contains(t, names, name)
+		}
+		return true
+	})
+	assert.NotZero(t, refs)
+
+	// The titles of the schemas have no prefix and suffix.
+	assert.Equal(t, "XXX", api.Components.Schemas["AppXXXV1"].Title)
+	assert.Equal(t, "OpenapiY", api.Components.Schemas["AppOpenapiYV1"].Title)
+}
+
+// TestSchemaTitle tests that the component schemas of
+// the struct types are titled after their name, unlike
+// the inlined schemas of the anonymous structs.
+func TestSchemaTitle(t *testing.T) {
+	type T struct {
+		X X
+		A struct {
+			B string
+		}
+	}
+	g := gen(t)
+
+	sor := g.newSchemaFromType(rt(T{}), tonic.MediaType())
+	schema := g.resolveSchema(sor)
+	if !assert.NotNil(t, schema) {
+		return
+	}
+	assert.Equal(t, "T", schema.Title)
+	assert.Empty(t, schema.Properties["A"].Title)
+
+	x := g.API().Components.Schemas["XXX"]
+	if assert.NotNil(t, x) {
+		assert.Equal(t, "XXX", x.Title)
+	}
+	assert.Equal(t, "Y", g.API().Components.Schemas["Y"].Title)
+}
+
+type (
+	HttpResult[T any] struct {
+		Code int `json:"code"`
+		Data T   `json:"data"`
+	}
+	Pair[K comparable, V any] struct {
+		Key   K `json:"key"`
+		Value V `json:"value"`
+	}
+	FileUploadResp struct {
+		URL string `json:"url"`
+	}
+)
+
+// TestGenericTypeName tests that the names of the
+// instantiated generic types contain the names of
+// their type arguments, without their packages.
+func TestGenericTypeName(t *testing.T) {
+	g := gen(t)
+
+	for _, tc := range []struct {
+		typ       reflect.Type
+		name      string
+		fullName  string
+		component string
+	}{
+		{rt(HttpResult[FileUploadResp]{}), "HttpResultFileUploadResp", "OpenapiHttpResultOpenapiFileUploadResp", "HttpResultFileUploadResp"},
+		{rt(HttpResult[[]FileUploadResp]{}), "HttpResultArrayFileUploadResp", "OpenapiHttpResultArrayOpenapiFileUploadResp", "HttpResultArrayFileUploadResp"},
+		{rt(&HttpResult[*FileUploadResp]{}), "HttpResultFileUploadResp", "OpenapiHttpResultOpenapiFileUploadResp", "HttpResultFileUploadResp"},
+		{rt(HttpResult[HttpResult[int]]{}), "HttpResultHttpResultInt", "OpenapiHttpResultOpenapiHttpResultInt", "HttpResultHttpResultInt"},
+		{rt(HttpResult[map[string]time.Time]{}), "HttpResultMapStringTime", "OpenapiHttpResultMapStringTimeTime", "HttpResultMapStringTime"},
+		{rt(Pair[string, FileUploadResp]{}), "PairStringFileUploadResp", "OpenapiPairStringOpenapiFileUploadResp", "PairStringFileUploadResp"},
+	} {
+		g.UseFullSchemaNames(false)
+		assert.Equal(t, tc.name, g.typeName(tc.typ))
+		g.UseFullSchemaNames(true)
+		assert.Equal(t, tc.fullName, g.typeName(tc.typ))
+
+		g.UseFullSchemaNames(false)
+		sor := g.newSchemaFromType(tc.typ, tonic.MediaType())
+		if assert.NotNil(t, sor) {
+			assert.Equal(t, componentsSchemaPath+tc.component, sor.Ref)
+		}
+	}
+	assert.Empty(t, g.Errors())
 }
@@ -0,0 +1,88 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ccfish86/gadgeto/tonic"
+)
+
+const (
+	jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	jsonSchemaDefPath = "#/$defs/"
+)
+
+// JSONSchemaFor returns a standalone JSON Schema, in
+// the draft 2020-12 of the specification, that describes
+// the type t. The component schemas referenced by the
+// schema of the type are added to its $defs, and the
+// references point to these definitions instead of the
+// components of the specification.
+func (g *Generator) JSONSchemaFor(t reflect.Type) ([]byte, error) {
+	sor := g.newSchemaFromType(t, tonic.MediaType())
+	if sor == nil {
+		return nil, fmt.Errorf("cannot generate the schema of type %s", t)
+	}
+	seen := make(map[copyKey]reflect.Value)
+
+	// The schema of a named struct is a reference to
+	// its component, which becomes the root schema.
+	if sor.Reference != nil {
+		if c, ok := g.api.Components.Schemas[strings.TrimPrefix(sor.Ref, componentsSchemaPath)]; ok && c != nil {
+			sor = c
+		}
+	}
+	root := deepCopy(reflect.ValueOf(sor), seen).Interface().(*SchemaOrRef)
+	defs := make(map[string]*SchemaOrRef)
+
+	var visit func(sor *SchemaOrRef, _ bool) bool
+	visit = func(sor *SchemaOrRef, _ bool) bool {
+		if sor.Reference != nil {
+			name := strings.TrimPrefix(sor.Ref, componentsSchemaPath)
+			if name == sor.Ref {
+				// Already rewritten, or not a
+				// reference to a component schema.
+				return false
+			}
+			sor.Ref = jsonSchemaDefPath + name
+
+			if _, ok := defs[name]; !ok {
+				if c, ok := g.api.Components.Schemas[name]; ok && c != nil {
+					def := deepCopy(reflect.ValueOf(c), seen).Interface().(*SchemaOrRef)
+					defs[name] = def
+					walkSchemaRef(def, true, visit)
+				}
+			}
+			return false
+		}
+		if sor.Schema != nil {
+			sor.Schema.v31 = true
+		}
+		return true
+	}
+	walkSchemaRef(root, true, visit)
+
+	b, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+	// A schema with neither a schema nor a
+	// reference is the boolean schema true.
+	m := make(map[string]json.RawMessage)
+	if root.Schema != nil || root.Reference != nil {
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, err
+		}
+	}
+	if m["$schema"], err = json.Marshal(jsonSchemaDialect); err != nil {
+		return nil, err
+	}
+	if len(defs) != 0 {
+		if m["$defs"], err = json.Marshal(defs); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(m)
+}
@@ -0,0 +1,236 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// MergeSpec merges the paths, the components schemas, the
+// security schemes and the tags of another specification
+// into the generated one. The paths of the other spec are
+// prefixed with pathPrefix. The merge fails if an operation
+// already exists for the same path and method, or if the
+// other spec declares a component with the same name but a
+// different definition, in which case the generated spec is
+// left unchanged. Identical components are merged once.
+// The paths and components of the other spec are copied,
+// and can be modified afterward without affecting the
+// generated spec.
+func (g *Generator) MergeSpec(other *OpenAPI, pathPrefix string) error {
+	if other == nil {
+		return nil
+	}
+	other = deepCopy(reflect.ValueOf(other), make(map[copyKey]reflect.Value)).Interface().(*OpenAPI)
+
+	paths := make(Paths, len(other.Paths))
+	for path, item := range other.Paths {
+		if item != nil {
+			paths[joinPaths(pathPrefix, path)] = item
+		}
+	}
+	if err := g.checkMergeSpec(other, paths); err != nil {
+		return err
+	}
+	g.finalized = false
+
+	for path, item := range paths {
+		existing, ok := g.api.Paths[path]
+		if !ok {
+			g.api.Paths[path] = item
+			continue
+		}
+		if existing.Summary == "" {
+			existing.Summary = item.Summary
+		}
+		if existing.Description == "" {
+			existing.Description = item.Description
+		}
+		// The path-level parameters of the other spec must
+		// not apply to the existing operations, they are
+		// moved to the merged operations instead.
+		for method, op := range item.operationsByMethod() {
+			op.Parameters = mergeParameters(item.Parameters, op.Parameters)
+			setOperationBymethod(existing, op, method)
+		}
+	}
+	for _, op := range paths.operations() {
+		if op.ID != "" {
+			g.operationsIDS[op.ID] = struct{}{}
+		}
+		g.setDefaultResponses(op, g.defaultResps...)
+	}
+	if other.Components != nil {
+		for name, sor := range other.Components.Schemas {
+			if _, ok := g.api.Components.Schemas[name]; !ok {
+				g.api.Components.Schemas[name] = sor
+			}
+		}
+		for name, ssor := range other.Components.SecuritySchemes {
+			if g.api.Components.SecuritySchemes == nil {
+				g.api.Components.SecuritySchemes = make(map[string]*SecuritySchemeOrRef)
+			}
+			if _, ok := g.api.Components.SecuritySchemes[name]; !ok {
+				g.api.Components.SecuritySchemes[name] = ssor
+			}
+		}
+	}
+	for _, tag := range other.Tags {
+		if tag == nil {
+			continue
+		}
+		if _, ok := g.TagByName(tag.Name); !ok {
+			g.AddTag(tag.Name, tag.Description)
+		}
+	}
+	return nil
+}
+
+// checkMergeSpec returns an error if the prefixed paths
+// or the components of the other spec conflict with the
+// ones of the generated spec.
+func (g *Generator) checkMergeSpec(other *OpenAPI, paths Paths) error {
+	for path, item := range paths {
+		existing, ok := g.api.Paths[path]
+		if !ok {
+			continue
+		}
+		ops := existing.operationsByMethod()
+		for method := range item.operationsByMethod() {
+			if _, ok := ops[method]; ok {
+				return fmt.Errorf("operation %s %s already exists", method, path)
+			}
+		}
+	}
+	ids := make(map[string]struct{})
+	for _, op := range paths.operations() {
+		if op.ID == "" {
+			continue
+		}
+		if _, ok := g.operationsIDS[op.ID]; ok {
+			return fmt.Errorf("ID %s is already used by another operation", op.ID)
+		}
+		if _, ok := ids[op.ID]; ok {
+			return fmt.Errorf("ID %s is used by several operations of the merged spec", op.ID)
+		}
+		ids[op.ID] = struct{}{}
+	}
+	if other.Components == nil {
+		return nil
+	}
+	for name, sor := range other.Components.Schemas {
+		if existing, ok := g.api.Components.Schemas[name]; ok && !sameDefinition(existing, sor) {
+			return fmt.Errorf("schema %s already exists with a different definition", name)
+		}
+	}
+	if g.api.Components.SecuritySchemes != nil {
+		for name, ssor := range other.Components.SecuritySchemes {
+			if existing, ok := g.api.Components.SecuritySchemes[name]; ok && !sameDefinition(existing, ssor) {
+				return fmt.Errorf("security scheme %s already exists with a different definition", name)
+			}
+		}
+	}
+	return nil
+}
+
+// mergeParameters returns the parameters of an operation,
+// preceded by the parameters of its path item that it
+// doesn't override, identified by their name and location.
+func mergeParameters(itemParams, opParams []*ParameterOrRef) []*ParameterOrRef {
+	if len(itemParams) == 0 {
+		return opParams
+	}
+	keys := make(map[string]struct{}, len(opParams))
+	for _, p := range opParams {
+		keys[parameterKey(p)] = struct{}{}
+	}
+	params := make([]*ParameterOrRef, 0, len(itemParams)+len(opParams))
+	for _, p := range itemParams {
+		if _, ok := keys[parameterKey(p)]; !ok && p != nil {
+			params = append(params, p)
+		}
+	}
+	return append(params, opParams...)
+}
+
+// parameterKey returns a key that identifies the
+// parameter p, or the reference to a parameter.
+func parameterKey(p *ParameterOrRef) string {
+	switch {
+	case p == nil:
+		return ""
+	case p.Reference != nil:
+		return p.Reference.Ref
+	case p.Parameter != nil:
+		return p.Parameter.In + ":" + p.Parameter.Name
+	}
+	return ""
+}
+
+// operations returns the operations of all the
+// paths, sorted by path to be deterministic.
+func (p Paths) operations() []*Operation {
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var ops []*Operation
+	for _, k := range keys {
+		if p[k] != nil {
+			ops = append(ops, p[k].operations()...)
+		}
+	}
+	return ops
+}
+
+// operationsByMethod returns the non-nil operations
+// of the path item, indexed by method.
+func (pi *PathItem) operationsByMethod() map[string]*Operation {
+	ops := make(map[string]*Operation)
+	for method, op := range map[string]*Operation{
+		"GET":     pi.GET,
+		"PUT":     pi.PUT,
+		"POST":    pi.POST,
+		"DELETE":  pi.DELETE,
+		"OPTIONS": pi.OPTIONS,
+		"HEAD":    pi.HEAD,
+		"PATCH":   pi.PATCH,
+		"TRACE":   pi.TRACE,
+	} {
+		if op != nil {
+			ops[method] = op
+		}
+	}
+	return ops
+}
+
+// sameDefinition returns whether the JSON representations
+// of the definitions of two components are identical.
+func sameDefinition(a, b interface{}) bool {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	var va, vb interface{}
+	if json.Unmarshal(ja, &va) != nil || json.Unmarshal(jb, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// joinPaths joins a path prefix and a path
+// with a single slash between them.
+func joinPaths(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
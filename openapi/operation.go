@@ -6,15 +6,37 @@ type OperationInfo struct {
 	ID                string
 	StatusCode        int
 	StatusDescription string
+	StatusContents    map[string]interface{}
 	Headers           []*ResponseHeader
 	Summary           string
 	Description       string
+	Tags              []string
+	ExternalDocs      *ExternalDocumentation
+	Servers           []*Server
 	Deprecated        bool
 	InputModel        interface{}
+	InputSchema       *Schema
+	InputMediaType    string
+	RequestExample    interface{}
+	RequestExamples   map[string]interface{}
+	BodyRequired      *bool
 	Responses         []*OperationResponse
+	ResponseExamples  map[string]map[string]interface{}
+	Callbacks         map[string]*Callback
+	Links             []*OperationLink
 	Security          []*SecurityRequirement
 	XCodeSamples      []*XCodeSample
 	XInternal         bool
+	Hidden            bool
+	Extensions        map[string]interface{}
+}
+
+// OperationLink represents a link from a response
+// of an API operation to another operation.
+type OperationLink struct {
+	Code string
+	Name string
+	Link *Link
 }
 
 // ResponseHeader represents a single header that
@@ -23,6 +45,9 @@ type ResponseHeader struct {
 	Name        string
 	Description string
 	Model       interface{}
+	Required    bool
+	Deprecated  bool
+	Example     interface{}
 }
 
 // OperationResponse represents a single response of an
@@ -36,4 +61,7 @@ type OperationResponse struct {
 	Headers     []*ResponseHeader
 	Example     interface{}
 	Examples    map[string]interface{}
+	// Contents maps additional media types
+	// of the response to their models.
+	Contents map[string]interface{}
 }
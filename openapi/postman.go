@@ -0,0 +1,359 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// postmanCollection represents a Postman collection,
+// as described by the version 2.1 of its format.
+type postmanCollection struct {
+	Info     postmanInfo        `json:"info"`
+	Item     []*postmanItem     `json:"item"`
+	Variable []*postmanVariable `json:"variable,omitempty"`
+}
+
+type postmanInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// postmanItem represents either a folder of
+// items, or a request if Request is not nil.
+type postmanItem struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Item        []*postmanItem  `json:"item,omitempty"`
+	Request     *postmanRequest `json:"request,omitempty"`
+}
+
+type postmanRequest struct {
+	Method      string             `json:"method"`
+	Description string             `json:"description,omitempty"`
+	Header      []*postmanVariable `json:"header"`
+	URL         *postmanURL        `json:"url"`
+	Body        *postmanBody       `json:"body,omitempty"`
+}
+
+type postmanURL struct {
+	Raw      string             `json:"raw"`
+	Host     []string           `json:"host"`
+	Path     []string           `json:"path,omitempty"`
+	Query    []*postmanVariable `json:"query,omitempty"`
+	Variable []*postmanVariable `json:"variable,omitempty"`
+}
+
+type postmanVariable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+type postmanBody struct {
+	Mode    string              `json:"mode"`
+	Raw     string              `json:"raw"`
+	Options *postmanBodyOptions `json:"options,omitempty"`
+}
+
+type postmanBodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+// ExportPostman writes a Postman collection, in the
+// version 2.1 of its format, of the operations of the
+// specification to w. The requests are grouped in one
+// folder per tag, using the first tag of the operations,
+// and their URLs are relative to a baseUrl variable set
+// to the URL of the first server of the specification.
+// The request bodies are filled with examples built from
+// the schemas.
+func (g *Generator) ExportPostman(w io.Writer) error {
+	api := g.API()
+
+	c := &postmanCollection{
+		Info: postmanInfo{Schema: postmanSchema},
+	}
+	if api.Info != nil {
+		c.Info.Name = api.Info.Title
+		c.Info.Description = api.Info.Description
+	}
+	var baseURL string
+	if len(api.Servers) != 0 && api.Servers[0] != nil {
+		baseURL = strings.TrimSuffix(api.Servers[0].URL, "/")
+	}
+	c.Variable = []*postmanVariable{{Key: "baseUrl", Value: baseURL}}
+
+	folders := make(map[string]*postmanItem)
+	for _, tag := range api.Tags {
+		if tag != nil {
+			folders[tag.Name] = &postmanItem{Name: tag.Name, Description: tag.Description}
+		}
+	}
+	var (
+		tags  []string
+		items []*postmanItem
+	)
+	paths := make([]string, 0, len(api.Paths))
+	for path := range api.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		item := api.Paths[path]
+		if item == nil {
+			continue
+		}
+		methods := item.operationsByMethod()
+		for _, method := range []string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE"} {
+			op, ok := methods[method]
+			if !ok {
+				continue
+			}
+			req := g.postmanItem(path, method, op)
+			if len(op.Tags) == 0 {
+				items = append(items, req)
+				continue
+			}
+			f, ok := folders[op.Tags[0]]
+			if !ok {
+				f = &postmanItem{Name: op.Tags[0]}
+				folders[op.Tags[0]] = f
+			}
+			if len(f.Item) == 0 {
+				tags = append(tags, op.Tags[0])
+			}
+			f.Item = append(f.Item, req)
+		}
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		c.Item = append(c.Item, folders[tag])
+	}
+	c.Item = append(c.Item, items...)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(c)
+}
+
+// postmanItem returns the Postman request
+// item that describes an operation.
+func (g *Generator) postmanItem(path, method string, op *Operation) *postmanItem {
+	name := op.Summary
+	if name == "" {
+		name = op.ID
+	}
+	if name == "" {
+		name = method + " " + path
+	}
+	req := &postmanRequest{
+		Method:      method,
+		Description: op.Description,
+		Header:      []*postmanVariable{},
+		URL: &postmanURL{
+			Host: []string{"{{baseUrl}}"},
+		},
+	}
+	for _, seg := range strings.Split(strings.Trim(path, "/"), "/") {
+		if seg == "" {
+			continue
+		}
+		// Path parameters use the
+		// variables syntax of Postman.
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			seg = ":" + strings.TrimSuffix(strings.TrimPrefix(seg, "{"), "}")
+		}
+		req.URL.Path = append(req.URL.Path, seg)
+	}
+	for _, por := range op.Parameters {
+		p := g.resolveParameter(por)
+		if p == nil {
+			continue
+		}
+		v := &postmanVariable{
+			Key:         p.Name,
+			Value:       g.postmanParamValue(p),
+			Description: p.Description,
+		}
+		switch p.In {
+		case "path":
+			req.URL.Variable = append(req.URL.Variable, v)
+		case "query":
+			req.URL.Query = append(req.URL.Query, v)
+		case "header":
+			req.Header = append(req.Header, v)
+		}
+	}
+	raw := "{{baseUrl}}"
+	if len(req.URL.Path) != 0 {
+		raw += "/" + strings.Join(req.URL.Path, "/")
+	}
+	for i, q := range req.URL.Query {
+		sep := "&"
+		if i == 0 {
+			sep = "?"
+		}
+		raw += sep + url.QueryEscape(q.Key) + "=" + url.QueryEscape(q.Value)
+	}
+	req.URL.Raw = raw
+
+	if op.RequestBody != nil {
+		var mt string
+		req.Body, mt = g.postmanBody(op.RequestBody)
+		if req.Body != nil {
+			req.Header = append(req.Header, &postmanVariable{Key: "Content-Type", Value: mt})
+		}
+	}
+	return &postmanItem{
+		Name:    name,
+		Request: req,
+	}
+}
+
+// postmanBody returns the raw body of a request, filled
+// with an example of the first media type of the request
+// body in alphabetical order, and this media type.
+func (g *Generator) postmanBody(rb *RequestBody) (*postmanBody, string) {
+	mts := make([]string, 0, len(rb.Content))
+	for mt := range rb.Content {
+		mts = append(mts, mt)
+	}
+	if len(mts) == 0 {
+		return nil, ""
+	}
+	sort.Strings(mts)
+	mt := rb.Content[mts[0]]
+
+	var v interface{}
+	switch {
+	case mt == nil:
+		return nil, ""
+	case mt.Example != nil:
+		v = mt.Example
+	case len(mt.Examples) != 0:
+		names := make([]string, 0, len(mt.Examples))
+		for name := range mt.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if e := mt.Examples[names[0]]; e != nil && e.Example != nil {
+			v = e.Example.Value
+		}
+	default:
+		v = g.exampleFromSchema(mt.Schema, make(map[string]bool))
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, ""
+	}
+	body := &postmanBody{
+		Mode: "raw",
+		Raw:  string(b),
+	}
+	if strings.Contains(mts[0], "json") {
+		body.Options = &postmanBodyOptions{}
+		body.Options.Raw.Language = "json"
+	}
+	return body, mts[0]
+}
+
+// postmanParamValue returns the example
+// value of a parameter, as a string.
+func (g *Generator) postmanParamValue(p *Parameter) string {
+	var v interface{}
+	if p.Schema != nil {
+		if s := g.resolveSchema(p.Schema); s != nil {
+			switch {
+			case s.Example != nil:
+				v = s.Example
+			case s.Default != nil:
+				v = s.Default
+			case len(s.Enum) != 0:
+				v = s.Enum[0]
+			}
+		}
+	}
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// exampleFromSchema returns an example value that is
+// valid against the schema. The references that are
+// being visited are skipped to avoid infinite loops.
+func (g *Generator) exampleFromSchema(sor *SchemaOrRef, visiting map[string]bool) interface{} {
+	if sor == nil {
+		return nil
+	}
+	if sor.Reference != nil {
+		if visiting[sor.Ref] {
+			return nil
+		}
+		visiting[sor.Ref] = true
+		defer delete(visiting, sor.Ref)
+	}
+	s := g.resolveSchema(sor)
+	if s == nil {
+		return nil
+	}
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) != 0:
+		return s.Enum[0]
+	case len(s.OneOf) != 0:
+		return g.exampleFromSchema(s.OneOf[0], visiting)
+	case len(s.AnyOf) != 0:
+		return g.exampleFromSchema(s.AnyOf[0], visiting)
+	case len(s.AllOf) != 0:
+		m := make(map[string]interface{})
+		for _, sub := range s.AllOf {
+			if v, ok := g.exampleFromSchema(sub, visiting).(map[string]interface{}); ok {
+				for k, val := range v {
+					m[k] = val
+				}
+			}
+		}
+		return m
+	}
+	switch s.Type {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return "1970-01-01T00:00:00Z"
+		case "date":
+			return "1970-01-01"
+		}
+		return "string"
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "array":
+		if v := g.exampleFromSchema(s.Items, visiting); v != nil {
+			return []interface{}{v}
+		}
+		return []interface{}{}
+	case "object":
+		m := make(map[string]interface{})
+		for name, prop := range s.Properties {
+			m[name] = g.exampleFromSchema(prop, visiting)
+		}
+		return m
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RemoveOperation removes the operation registered for
+// the method and the path, which can use the syntax of the
+// Gin routes, and the path item if it has no operation left.
+// The component schemas that are no longer referenced are
+// kept, use RemoveUnusedSchemas to remove them.
+func (g *Generator) RemoveOperation(path, method string) error {
+	path = rewritePath(path)
+	method = strings.ToUpper(method)
+
+	item, ok := g.api.Paths[path]
+	if !ok || item == nil {
+		return fmt.Errorf("path %s does not exist", path)
+	}
+	op, ok := item.operationsByMethod()[method]
+	if !ok {
+		return fmt.Errorf("operation %s %s does not exist", method, path)
+	}
+	setOperationBymethod(item, nil, method)
+	delete(g.operationsIDS, op.ID)
+
+	if len(item.operations()) == 0 {
+		delete(g.api.Paths, path)
+	}
+	return nil
+}
+
+// RemoveUnusedSchemas removes the component schemas that
+// are not referenced, directly or through other components,
+// by the operations and the other components of the spec,
+// and returns their sorted names.
+func (g *Generator) RemoveUnusedSchemas() []string {
+	var (
+		used  = make(map[string]bool)
+		queue []string
+	)
+	mark := func(ref string) {
+		name := strings.TrimPrefix(ref, componentsSchemaPath)
+		if name != ref && !used[name] {
+			used[name] = true
+			queue = append(queue, name)
+		}
+	}
+	visit := func(sor *SchemaOrRef, root bool) bool {
+		// The component schemas are only
+		// used through their references.
+		if root {
+			return false
+		}
+		if sor.Reference != nil {
+			mark(sor.Ref)
+			return false
+		}
+		if sor.Schema != nil && sor.Discriminator != nil {
+			for _, ref := range sor.Discriminator.Mapping {
+				mark(ref)
+			}
+		}
+		return true
+	}
+	walkSchemaRefs(g.api, visit)
+
+	for _, r := range g.api.Components.Responses {
+		if r == nil || r.Response == nil {
+			continue
+		}
+		for _, mt := range r.Content {
+			if mt != nil && mt.MediaType != nil {
+				walkSchemaRef(mt.Schema, false, visit)
+			}
+		}
+	}
+	for len(queue) != 0 {
+		name := queue[0]
+		queue = queue[1:]
+		walkSchemaRef(g.api.Components.Schemas[name], false, visit)
+	}
+	var removed []string
+	for name := range g.api.Components.Schemas {
+		if !used[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+
+	for _, name := range removed {
+		delete(g.api.Components.Schemas, name)
+	}
+	// Forget the types and the deduplicated schemas of
+	// the removed components, to generate them again if
+	// they are used by an operation added afterward.
+	for t := range g.schemaTypes {
+		if !used[g.schemaName(t)] {
+			delete(g.schemaTypes, t)
+		}
+	}
+	for fp, name := range g.dedupedSchemas {
+		if !used[name] {
+			delete(g.dedupedSchemas, fp)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,431 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// styleDelimiters maps the styles of the parameters
+// to the delimiter of the values of the arrays that
+// are not exploded.
+var styleDelimiters = map[string]string{
+	"simple":         ",",
+	"form":           ",",
+	"spaceDelimited": " ",
+	"pipeDelimited":  "|",
+}
+
+// Operation returns the operation registered for the
+// method and the path, which can use either the syntax
+// of the Gin routes or of the specification for its
+// parameters, and whether it exists. The specification
+// is finalized first, so that the operation can be read
+// concurrently with the other readers of the spec.
+func (g *Generator) Operation(method, path string) (*Operation, bool) {
+	g.finalize()
+
+	item, ok := g.api.Paths[rewritePath(path)]
+	if !ok || item == nil {
+		return nil, false
+	}
+	op, ok := item.operationsByMethod()[strings.ToUpper(method)]
+
+	return op, ok
+}
+
+// ValidateRequest validates the parameters and the JSON
+// body of the request against the schemas of the operation,
+// and returns an error for each invalid value. The values
+// of the path parameters are read from pathParams. The body
+// of the request is restored to be read again by the handler.
+func (g *Generator) ValidateRequest(op *Operation, r *http.Request, pathParams map[string]string) []error {
+	var errs []error
+
+	for _, por := range op.Parameters {
+		if por == nil {
+			continue
+		}
+		p := g.resolveParameter(por)
+		if p == nil {
+			continue
+		}
+		var values []string
+		switch p.In {
+		case "path":
+			if v, ok := pathParams[p.Name]; ok {
+				values = []string{v}
+			}
+		case "query":
+			if p.Style == "deepObject" {
+				errs = append(errs, g.validateDeepObject(p, r.URL.Query())...)
+				continue
+			}
+			values = r.URL.Query()[p.Name]
+		case "header":
+			values = r.Header.Values(p.Name)
+		case "cookie":
+			if c, err := r.Cookie(p.Name); err == nil {
+				values = []string{c.Value}
+			}
+		}
+		errs = append(errs, g.validateParameter(p, values)...)
+	}
+	rb := g.resolveRequestBody(op.RequestBody)
+	if rb == nil {
+		return errs
+	}
+	mt := requestBodyJSONMediaType(rb, r.Header.Get("Content-Type"))
+	if mt == nil {
+		// Only the presence of a body that is
+		// not JSON can be validated.
+		if rb.Required && (r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0) {
+			errs = append(errs, fmt.Errorf("body: is required"))
+		}
+		return errs
+	}
+	var b []byte
+	if r.Body != nil {
+		var err error
+		if b, err = ioutil.ReadAll(r.Body); err != nil {
+			return append(errs, fmt.Errorf("body: %s", err))
+		}
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(b))
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		if rb.Required {
+			errs = append(errs, fmt.Errorf("body: is required"))
+		}
+		return errs
+	}
+	if mt.Schema == nil {
+		return errs
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return append(errs, fmt.Errorf("body: invalid JSON: %s", err))
+	}
+	return append(errs, g.validateValue(mt.Schema, v, "body")...)
+}
+
+// resolveRequestBody returns either the inlined request
+// body rb or the one referenced in the API components.
+func (g *Generator) resolveRequestBody(rb *RequestBody) *RequestBody {
+	if rb == nil || rb.Ref == "" {
+		return rb
+	}
+	if g.api.Components == nil {
+		return nil
+	}
+	return g.api.Components.RequestBodies[strings.TrimPrefix(rb.Ref, "#/components/requestBodies/")]
+}
+
+// requestBodyJSONMediaType returns the JSON media type of
+// the request body rb that describes the content of type
+// ct, such as application/json or any type with the +json
+// suffix. If ct is empty, or is a JSON media type that the
+// body doesn't declare, the first JSON media type of the
+// body is returned. It returns nil if ct is not JSON or
+// the body declares no JSON media type.
+func requestBodyJSONMediaType(rb *RequestBody, ct string) *MediaType {
+	if ct != "" {
+		t, _, err := mime.ParseMediaType(ct)
+		if err != nil || mediaTagName(t) != "json" {
+			return nil
+		}
+		if mt, ok := rb.Content[t]; ok && mt != nil {
+			return mt
+		}
+	}
+	types := make([]string, 0, len(rb.Content))
+	for t, mt := range rb.Content {
+		if mt != nil && mediaTagName(t) == "json" {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		return nil
+	}
+	sort.Strings(types)
+
+	return rb.Content[types[0]]
+}
+
+// validateParameter validates the values of a parameter,
+// given as strings, against the schema of the parameter.
+func (g *Generator) validateParameter(p *Parameter, values []string) []error {
+	at := fmt.Sprintf("%s parameter %s", p.In, p.Name)
+
+	if len(values) == 0 {
+		if p.Required {
+			return []error{fmt.Errorf("%s: is required", at)}
+		}
+		return nil
+	}
+	if p.Schema == nil {
+		return nil
+	}
+	s := g.resolveSchema(p.Schema)
+	if s == nil {
+		return nil
+	}
+	if s.Type != "array" {
+		v, err := g.parseParameterValue(s, values[0])
+		if err != nil {
+			return []error{fmt.Errorf("%s: %s", at, err)}
+		}
+		return g.validateValue(p.Schema, v, at)
+	}
+	// The values of an array that is not exploded
+	// are separated by the delimiter of its style.
+	if sep, ok := styleDelimiters[p.Style]; ok && len(values) == 1 && !p.Explode {
+		values = strings.Split(values[0], sep)
+	}
+	items := make([]interface{}, 0, len(values))
+	for i, value := range values {
+		var is *Schema
+		if s.Items != nil {
+			is = g.resolveSchema(s.Items)
+		}
+		v, err := g.parseParameterValue(is, value)
+		if err != nil {
+			return []error{fmt.Errorf("%s[%d]: %s", at, i, err)}
+		}
+		items = append(items, v)
+	}
+	return g.validateValue(p.Schema, items, at)
+}
+
+// validateDeepObject validates the properties of a query
+// parameter with the deepObject style, sent with keys such
+// as name[property], against the schema of the parameter.
+func (g *Generator) validateDeepObject(p *Parameter, query map[string][]string) []error {
+	at := fmt.Sprintf("%s parameter %s", p.In, p.Name)
+
+	var s *Schema
+	if p.Schema != nil {
+		s = g.resolveSchema(p.Schema)
+	}
+	obj := make(map[string]interface{})
+	for key, values := range query {
+		if len(values) == 0 || !strings.HasPrefix(key, p.Name+"[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		name := key[len(p.Name)+1 : len(key)-1]
+
+		var ps *Schema
+		if s != nil {
+			if sor, ok := s.Properties[name]; ok {
+				ps = g.resolveSchema(sor)
+			} else if s.AdditionalProperties != nil {
+				ps = g.resolveSchema(s.AdditionalProperties)
+			}
+		}
+		v, err := g.parseParameterValue(ps, values[0])
+		if err != nil {
+			return []error{fmt.Errorf("%s.%s: %s", at, name, err)}
+		}
+		obj[name] = v
+	}
+	if len(obj) == 0 {
+		if p.Required {
+			return []error{fmt.Errorf("%s: is required", at)}
+		}
+		return nil
+	}
+	return g.validateValue(p.Schema, obj, at)
+}
+
+// parseParameterValue converts the value of a parameter
+// to the type described by the schema. The numbers are
+// converted to JSON numbers, like the ones of the bodies.
+func (g *Generator) parseParameterValue(s *Schema, v string) (interface{}, error) {
+	if s == nil {
+		return v, nil
+	}
+	switch s.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return json.Number(v), nil
+	case "number":
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return json.Number(v), nil
+	case "boolean":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		return b, nil
+	}
+	return v, nil
+}
+
+// validateValue validates a value decoded from JSON against
+// the schema, and returns an error for each invalid value,
+// prefixed with its location.
+func (g *Generator) validateValue(sor *SchemaOrRef, v interface{}, at string) []error {
+	if sor == nil {
+		return nil
+	}
+	s := g.resolveSchema(sor)
+	if s == nil {
+		// A schema with neither a schema nor
+		// a reference allows any value.
+		return nil
+	}
+	if v == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return []error{fmt.Errorf("%s: must not be null", at)}
+	}
+	var errs []error
+
+	for _, sub := range s.AllOf {
+		errs = append(errs, g.validateValue(sub, v, at)...)
+	}
+	for _, alts := range [][]*SchemaOrRef{s.OneOf, s.AnyOf} {
+		if len(alts) == 0 {
+			continue
+		}
+		var valid bool
+		for _, alt := range alts {
+			if len(g.validateValue(alt, v, at)) == 0 {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			errs = append(errs, fmt.Errorf("%s: must match one of the schemas", at))
+		}
+	}
+	if len(s.Enum) != 0 {
+		var found bool
+		for _, e := range s.Enum {
+			if fmt.Sprint(e) == fmt.Sprint(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("%s: must be one of %v", at, s.Enum))
+		}
+	}
+	switch s.Type {
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return append(errs, fmt.Errorf("%s: must be a string", at))
+		}
+		n := utf8.RuneCountInString(str)
+		if s.MinLength != 0 && n < s.MinLength {
+			errs = append(errs, fmt.Errorf("%s: must be at least %d characters long", at, s.MinLength))
+		}
+		if s.MaxLength != 0 && n > s.MaxLength {
+			errs = append(errs, fmt.Errorf("%s: must be at most %d characters long", at, s.MaxLength))
+		}
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(str) {
+				errs = append(errs, fmt.Errorf("%s: must match the pattern %s", at, s.Pattern))
+			}
+		}
+	case "integer", "number":
+		n, ok := v.(json.Number)
+		if !ok {
+			return append(errs, fmt.Errorf("%s: must be a number", at))
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return append(errs, fmt.Errorf("%s: must be a number", at))
+		}
+		if s.Type == "integer" && f != math.Trunc(f) {
+			return append(errs, fmt.Errorf("%s: must be an integer", at))
+		}
+		errs = append(errs, validateBounds(s, f, at)...)
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			errs = append(errs, fmt.Errorf("%s: must be a boolean", at))
+		}
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok {
+			return append(errs, fmt.Errorf("%s: must be an array", at))
+		}
+		if s.MinItems != 0 && len(items) < s.MinItems {
+			errs = append(errs, fmt.Errorf("%s: must have at least %d items", at, s.MinItems))
+		}
+		if s.MaxItems != 0 && len(items) > s.MaxItems {
+			errs = append(errs, fmt.Errorf("%s: must have at most %d items", at, s.MaxItems))
+		}
+		for i, item := range items {
+			errs = append(errs, g.validateValue(s.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case "object":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return append(errs, fmt.Errorf("%s: must be an object", at))
+		}
+		if s.MinProperties != 0 && len(m) < s.MinProperties {
+			errs = append(errs, fmt.Errorf("%s: must have at least %d properties", at, s.MinProperties))
+		}
+		if s.MaxProperties != 0 && len(m) > s.MaxProperties {
+			errs = append(errs, fmt.Errorf("%s: must have at most %d properties", at, s.MaxProperties))
+		}
+		for _, name := range s.Required {
+			if _, ok := m[name]; !ok {
+				errs = append(errs, fmt.Errorf("%s.%s: is required", at, name))
+			}
+		}
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if p, ok := s.Properties[name]; ok {
+				errs = append(errs, g.validateValue(p, m[name], at+"."+name)...)
+			} else if s.AdditionalProperties != nil {
+				errs = append(errs, g.validateValue(s.AdditionalProperties, m[name], at+"."+name)...)
+			}
+		}
+	}
+	return errs
+}
+
+// validateBounds validates a number against the
+// minimum and maximum of the schema.
+func validateBounds(s *Schema, f float64, at string) []error {
+	var errs []error
+
+	if s.Minimum != nil {
+		if s.ExclusiveMinimum && f <= *s.Minimum {
+			errs = append(errs, fmt.Errorf("%s: must be greater than %v", at, *s.Minimum))
+		} else if f < *s.Minimum {
+			errs = append(errs, fmt.Errorf("%s: must be greater than or equal to %v", at, *s.Minimum))
+		}
+	}
+	if s.Maximum != nil {
+		if s.ExclusiveMaximum && f >= *s.Maximum {
+			errs = append(errs, fmt.Errorf("%s: must be lower than %v", at, *s.Maximum))
+		} else if f > *s.Maximum {
+			errs = append(errs, fmt.Errorf("%s: must be lower than or equal to %v", at, *s.Maximum))
+		}
+	}
+	return errs
+}
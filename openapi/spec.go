@@ -1,18 +1,25 @@
 package openapi
 
-import "encoding/json"
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
 
 // OpenAPI represents the root document object of
 // an OpenAPI document.
 type OpenAPI struct {
-	OpenAPI    string                 `json:"openapi" yaml:"openapi"`
-	Info       *Info                  `json:"info" yaml:"info"`
-	Servers    []*Server              `json:"servers,omitempty" yaml:"servers,omitempty"`
-	Paths      Paths                  `json:"paths" yaml:"paths"`
-	Components *Components            `json:"components,omitempty" yaml:"components,omitempty"`
-	Tags       []*Tag                 `json:"tags,omitempty" yaml:"tags,omitempty"`
-	Security   []*SecurityRequirement `json:"security,omitempty" yaml:"security,omitempty"`
-	XTagGroups []*XTagGroup           `json:"x-tagGroups,omitempty" yaml:"x-tagGroups,omitempty"`
+	OpenAPI      string                 `json:"openapi" yaml:"openapi"`
+	Info         *Info                  `json:"info" yaml:"info"`
+	Servers      []*Server              `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Paths        Paths                  `json:"paths" yaml:"paths"`
+	Components   *Components            `json:"components,omitempty" yaml:"components,omitempty"`
+	Tags         []*Tag                 `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Security     []*SecurityRequirement `json:"security,omitempty" yaml:"security,omitempty"`
+	ExternalDocs *ExternalDocumentation `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+	XTagGroups   []*XTagGroup           `json:"x-tagGroups,omitempty" yaml:"x-tagGroups,omitempty"`
 }
 
 // Components holds a set of reusable objects for different
@@ -22,6 +29,7 @@ type Components struct {
 	Responses       map[string]*ResponseOrRef       `json:"responses,omitempty" yaml:"responses,omitempty"`
 	Parameters      map[string]*ParameterOrRef      `json:"parameters,omitempty" yaml:"parameters,omitempty"`
 	Examples        map[string]*ExampleOrRef        `json:"examples,omitempty" yaml:"examples,omitempty"`
+	RequestBodies   map[string]*RequestBody         `json:"requestBodies,omitempty" yaml:"requestBodies,omitempty"`
 	Headers         map[string]*HeaderOrRef         `json:"headers,omitempty" yaml:"headers,omitempty"`
 	SecuritySchemes map[string]*SecuritySchemeOrRef `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
 }
@@ -29,6 +37,7 @@ type Components struct {
 // Info represents the metadata of an API.
 type Info struct {
 	Title          string   `json:"title" yaml:"title"`
+	Summary        string   `json:"summary,omitempty" yaml:"summary,omitempty"`
 	Description    string   `json:"description,omitempty" yaml:"description,omitempty"`
 	TermsOfService string   `json:"termsOfService,omitempty" yaml:"termsOfService,omitempty"`
 	Contact        *Contact `json:"contact,omitempty" yaml:"contact,omitempty"`
@@ -52,6 +61,13 @@ type License struct {
 	URL  string `json:"url,omitempty" yaml:"url,omitempty"`
 }
 
+// ExternalDocumentation represents a reference to an
+// external resource for extended documentation.
+type ExternalDocumentation struct {
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	URL         string `json:"url" yaml:"url"`
+}
+
 // Server represents a server.
 type Server struct {
 	URL         string                     `json:"url" yaml:"url"`
@@ -124,15 +140,21 @@ func (por *ParameterOrRef) MarshalYAML() (interface{}, error) {
 	return por.Reference, nil
 }
 
-// RequestBody represents a request body.
+// RequestBody represents a request body, or a reference
+// to a request body of the components if Ref is set.
 type RequestBody struct {
+	Ref         string                `json:"$ref,omitempty" yaml:"$ref,omitempty"`
 	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
-	Content     map[string]*MediaType `json:"content" yaml:"content"`
+	Content     map[string]*MediaType `json:"content,omitempty" yaml:"content,omitempty"`
 	Required    bool                  `json:"required,omitempty" yaml:"required,omitempty"`
 }
 
 // SchemaOrRef represents a Schema that can be inlined
-// or referenced in the API description.
+// or referenced in the API description. A SchemaOrRef
+// with neither a schema nor a reference allows values
+// of any type, and is marshaled as the boolean schema
+// true, which OpenAPI 3.0 accepts only as additional
+// properties.
 type SchemaOrRef struct {
 	*Schema
 	*Reference
@@ -141,9 +163,28 @@ type SchemaOrRef struct {
 // MarshalYAML implements yaml.Marshaler for SchemaOrRef.
 func (sor *SchemaOrRef) MarshalYAML() (interface{}, error) {
 	if sor.Schema != nil {
+		if sor.Schema.v31 || len(sor.Schema.propertyOrder) != 0 || len(sor.Schema.extensions()) != 0 {
+			return sor.Schema.MarshalYAML()
+		}
 		return sor.Schema, nil
 	}
-	return sor.Reference, nil
+	if sor.Reference != nil {
+		return sor.Reference, nil
+	}
+	return true, nil
+}
+
+// MarshalJSON implements json.Marshaler for SchemaOrRef.
+// It is required to prevent the promoted method of the
+// embedded Schema from being called with a nil receiver.
+func (sor *SchemaOrRef) MarshalJSON() ([]byte, error) {
+	if sor.Schema != nil {
+		return json.Marshal(sor.Schema)
+	}
+	if sor.Reference != nil {
+		return json.Marshal(sor.Reference)
+	}
+	return []byte("true"), nil
 }
 
 // Schema represents the definition of input and output data
@@ -153,9 +194,9 @@ type Schema struct {
 	// definition but their definitions were adjusted to the
 	// OpenAPI Specification.
 	Type                 string                  `json:"type,omitempty" yaml:"type,omitempty"`
-	AllOf                *SchemaOrRef            `json:"allOf,omitempty" yaml:"allOf,omitempty"`
-	OneOf                *SchemaOrRef            `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
-	AnyOf                *SchemaOrRef            `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+	AllOf                []*SchemaOrRef          `json:"allOf,omitempty" yaml:"allOf,omitempty"`
+	OneOf                []*SchemaOrRef          `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	AnyOf                []*SchemaOrRef          `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
 	Items                *SchemaOrRef            `json:"items,omitempty" yaml:"items,omitempty"`
 	Properties           map[string]*SchemaOrRef `json:"properties,omitempty" yaml:"properties,omitempty"`
 	AdditionalProperties *SchemaOrRef            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
@@ -163,14 +204,15 @@ type Schema struct {
 	Format               string                  `json:"format,omitempty" yaml:"format,omitempty"`
 	Default              interface{}             `json:"default,omitempty" yaml:"default,omitempty"`
 	Example              interface{}             `json:"example,omitempty" yaml:"example,omitempty"`
+	Discriminator        *Discriminator          `json:"discriminator,omitempty" yaml:"discriminator,omitempty"`
 
 	// The following properties are taken directly from the
 	// JSON Schema definition and follow the same specifications
 	Title            string        `json:"title,omitempty" yaml:"title,omitempty"`
-	MultipleOf       int           `json:"multipleOf,omitempty" yaml:"multipleOf,omitempty"`
-	Maximum          int           `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	MultipleOf       float64       `json:"multipleOf,omitempty" yaml:"multipleOf,omitempty"`
+	Maximum          *float64      `json:"maximum,omitempty" yaml:"maximum,omitempty"`
 	ExclusiveMaximum bool          `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
-	Minimum          int           `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Minimum          *float64      `json:"minimum,omitempty" yaml:"minimum,omitempty"`
 	ExclusiveMinimum bool          `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
 	MaxLength        int           `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
 	MinLength        int           `json:"minLength,omitempty" yaml:"minLength,omitempty"`
@@ -184,6 +226,314 @@ type Schema struct {
 	Enum             []interface{} `json:"enum,omitempty" yaml:"enum,omitempty"`
 	Nullable         bool          `json:"nullable,omitempty" yaml:"nullable,omitempty"`
 	Deprecated       bool          `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	ReadOnly         bool          `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+	WriteOnly        bool          `json:"writeOnly,omitempty" yaml:"writeOnly,omitempty"`
+	XEnumVarNames    []string      `json:"x-enum-varnames,omitempty" yaml:"x-enum-varnames,omitempty"`
+	XEnumDescs       []string      `json:"x-enum-descriptions,omitempty" yaml:"x-enum-descriptions,omitempty"`
+
+	// Extensions holds the specification extensions
+	// of the schema, whose names start with x-.
+	Extensions map[string]interface{} `json:"-" yaml:"-"`
+
+	// RequiredConditions holds the conditions under
+	// which the properties of the schema are required.
+	RequiredConditions []*RequiredCondition `json:"-" yaml:"-"`
+
+	// v31 indicates that the schema must be marshaled
+	// according to the OpenAPI 3.1 specification, which
+	// is fully compatible with JSON Schema.
+	v31 bool
+
+	// propertyOrder holds the names of the properties
+	// in the order of the fields of the struct from
+	// which the schema was generated.
+	propertyOrder []string
+}
+
+// RequiredCondition represents a property of an object
+// that is required under a condition, described by the
+// name of a validator, such as required_with, and its
+// parameters. The fields are the names of the other
+// properties of the object, and the values of the
+// required_if validator are the values of these
+// properties that make the property required.
+type RequiredCondition struct {
+	Property  string   `json:"property" yaml:"property"`
+	Validator string   `json:"validator" yaml:"validator"`
+	Fields    []string `json:"fields" yaml:"fields"`
+	Values    []string `json:"values,omitempty" yaml:"values,omitempty"`
+}
+
+// Discriminator represents the property of a payload
+// that is used to differentiate between the alternative
+// schemas it may be validated against.
+type Discriminator struct {
+	PropertyName string            `json:"propertyName" yaml:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty" yaml:"mapping,omitempty"`
+}
+
+// schema is an alias of Schema without
+// its marshaling methods.
+type schema Schema
+
+// schema31 holds the properties of a schema that
+// are described differently in OpenAPI 3.1.
+type schema31 struct {
+	Type              interface{}
+	Minimum           *float64
+	ExclusiveMinimum  *float64
+	Maximum           *float64
+	ExclusiveMaximum  *float64
+	DependentRequired map[string][]string
+}
+
+// to31 returns the properties of the schema
+// converted to their OpenAPI 3.1 form.
+func (s *Schema) to31() *schema31 {
+	s31 := &schema31{
+		Minimum: s.Minimum,
+		Maximum: s.Maximum,
+	}
+	if s.Type != "" {
+		if s.Nullable {
+			s31.Type = []string{s.Type, "null"}
+		} else {
+			s31.Type = s.Type
+		}
+	}
+	// In JSON Schema, the exclusive bounds are
+	// numbers instead of modifiers of the bounds.
+	if s.ExclusiveMinimum && s.Minimum != nil {
+		s31.Minimum, s31.ExclusiveMinimum = nil, s.Minimum
+	}
+	if s.ExclusiveMaximum && s.Maximum != nil {
+		s31.Maximum, s31.ExclusiveMaximum = nil, s.Maximum
+	}
+	// A property required with other properties
+	// depends on the presence of these properties.
+	for _, c := range s.RequiredConditions {
+		if c.Validator != "required_with" {
+			continue
+		}
+		if s31.DependentRequired == nil {
+			s31.DependentRequired = make(map[string][]string)
+		}
+		for _, f := range c.Fields {
+			if !containsString(s31.DependentRequired[f], c.Property) {
+				s31.DependentRequired[f] = append(s31.DependentRequired[f], c.Property)
+			}
+		}
+	}
+	return s31
+}
+
+// extensions returns the specification extensions of
+// the schema, including the required conditions that
+// are not described by the dependentRequired keyword
+// of OpenAPI 3.1.
+func (s *Schema) extensions() map[string]interface{} {
+	var conds []*RequiredCondition
+	for _, c := range s.RequiredConditions {
+		if !s.v31 || c.Validator != "required_with" {
+			conds = append(conds, c)
+		}
+	}
+	if len(conds) == 0 {
+		return s.Extensions
+	}
+	ext := make(map[string]interface{}, len(s.Extensions)+1)
+	for k, v := range s.Extensions {
+		ext[k] = v
+	}
+	ext["x-required-conditions"] = conds
+
+	return ext
+}
+
+// propertyNames returns the names of the properties of
+// the schema, in the order of the fields of the struct
+// from which the schema was generated, followed by the
+// other properties sorted by name.
+func (s *Schema) propertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	seen := make(map[string]bool, len(s.Properties))
+
+	for _, name := range s.propertyOrder {
+		if _, ok := s.Properties[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+	var others []string
+	for name := range s.Properties {
+		if !seen[name] {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+
+	return append(names, others...)
+}
+
+// orderedProperties represents the properties of a
+// schema that are marshaled in the order of their names.
+type orderedProperties struct {
+	props map[string]*SchemaOrRef
+	names []string
+}
+
+// newOrderedProperties returns the ordered properties
+// of the schema, or nil if it has no properties.
+func (s *Schema) newOrderedProperties() *orderedProperties {
+	if len(s.Properties) == 0 {
+		return nil
+	}
+	return &orderedProperties{
+		props: s.Properties,
+		names: s.propertyNames(),
+	}
+}
+
+// MarshalJSON implements json.Marshaler for orderedProperties.
+func (op *orderedProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	for i, name := range op.names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kb, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := json.Marshal(op.props[name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// orderYAMLProperties replaces the properties of the
+// schema marshaled to the ordered map ms with the ones
+// ordered like the fields of the struct of the schema.
+func (s *Schema) orderYAMLProperties(ms yaml.MapSlice) {
+	for i, item := range ms {
+		if item.Key != "properties" {
+			continue
+		}
+		props := make(yaml.MapSlice, 0, len(s.Properties))
+		for _, name := range s.propertyNames() {
+			props = append(props, yaml.MapItem{Key: name, Value: s.Properties[name]})
+		}
+		ms[i].Value = props
+	}
+}
+
+// MarshalJSON implements json.Marshaler for Schema.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	// The fields of the outer struct have precedence
+	// over the ones of the embedded schema.
+	if !s.v31 {
+		return marshalJSONWithExtensions(&struct {
+			Type       string             `json:"type,omitempty"`
+			Properties *orderedProperties `json:"properties,omitempty"`
+			*schema
+		}{
+			Type:       s.Type,
+			Properties: s.newOrderedProperties(),
+			schema:     (*schema)(s),
+		}, s.extensions())
+	}
+	s31 := s.to31()
+
+	// Nullable is always omitted because it doesn't
+	// exist in OpenAPI 3.1 and the null type is used
+	// instead.
+	return marshalJSONWithExtensions(&struct {
+		Type       interface{}        `json:"type,omitempty"`
+		Properties *orderedProperties `json:"properties,omitempty"`
+		*schema
+		Nullable          bool                `json:"nullable,omitempty"`
+		Minimum           *float64            `json:"minimum,omitempty"`
+		ExclusiveMinimum  *float64            `json:"exclusiveMinimum,omitempty"`
+		Maximum           *float64            `json:"maximum,omitempty"`
+		ExclusiveMaximum  *float64            `json:"exclusiveMaximum,omitempty"`
+		DependentRequired map[string][]string `json:"dependentRequired,omitempty"`
+	}{
+		Type:              s31.Type,
+		Properties:        s.newOrderedProperties(),
+		schema:            (*schema)(s),
+		Minimum:           s31.Minimum,
+		ExclusiveMinimum:  s31.ExclusiveMinimum,
+		Maximum:           s31.Maximum,
+		ExclusiveMaximum:  s31.ExclusiveMaximum,
+		DependentRequired: s31.DependentRequired,
+	}, s.extensions())
+}
+
+// MarshalYAML implements yaml.Marshaler for Schema.
+func (s *Schema) MarshalYAML() (interface{}, error) {
+	if !s.v31 && len(s.propertyOrder) == 0 {
+		return marshalYAMLWithExtensions((*schema)(s), s.extensions())
+	}
+	// Marshal the schema to an ordered map to
+	// order the properties and rewrite the keys
+	// that differ in OpenAPI 3.1 while preserving
+	// the order of the fields.
+	b, err := yaml.Marshal((*schema)(s))
+	if err != nil {
+		return nil, err
+	}
+	var ms yaml.MapSlice
+	if err := yaml.Unmarshal(b, &ms); err != nil {
+		return nil, err
+	}
+	s.orderYAMLProperties(ms)
+
+	if !s.v31 {
+		return marshalYAMLWithExtensions(ms, s.extensions())
+	}
+	s31 := s.to31()
+
+	out := make(yaml.MapSlice, 0, len(ms))
+	for _, item := range ms {
+		switch item.Key {
+		case "nullable":
+			continue
+		case "type":
+			item.Value = s31.Type
+		case "minimum":
+			if s31.Minimum == nil {
+				continue
+			}
+		case "maximum":
+			if s31.Maximum == nil {
+				continue
+			}
+		case "exclusiveMinimum":
+			if s31.ExclusiveMinimum == nil {
+				continue
+			}
+			item.Value = *s31.ExclusiveMinimum
+		case "exclusiveMaximum":
+			if s31.ExclusiveMaximum == nil {
+				continue
+			}
+			item.Value = *s31.ExclusiveMaximum
+		}
+		out = append(out, item)
+	}
+	if len(s31.DependentRequired) != 0 {
+		out = append(out, yaml.MapItem{Key: "dependentRequired", Value: s31.DependentRequired})
+	}
+	return marshalYAMLWithExtensions(out, s.extensions())
 }
 
 // Operation describes an API operation on a path.
@@ -191,48 +541,60 @@ type Operation struct {
 	Tags         []string               `json:"tags,omitempty" yaml:"tags,omitempty"`
 	Summary      string                 `json:"summary,omitempty" yaml:"summary,omitempty"`
 	Description  string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	ExternalDocs *ExternalDocumentation `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
 	ID           string                 `json:"operationId,omitempty" yaml:"operationId,omitempty"`
 	Parameters   []*ParameterOrRef      `json:"parameters,omitempty" yaml:"parameters,omitempty"`
 	RequestBody  *RequestBody           `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
 	Responses    Responses              `json:"responses,omitempty" yaml:"responses,omitempty"`
+	Callbacks    map[string]*Callback   `json:"callbacks,omitempty" yaml:"callbacks,omitempty"`
 	Deprecated   bool                   `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
 	Servers      []*Server              `json:"servers,omitempty" yaml:"servers,omitempty"`
 	Security     []*SecurityRequirement `json:"security" yaml:"security"`
 	XCodeSamples []*XCodeSample         `json:"x-codeSamples,omitempty" yaml:"x-codeSamples,omitempty"`
 	XInternal    bool                   `json:"x-internal,omitempty" yaml:"x-internal,omitempty"`
+
+	// Extensions holds the specification extensions
+	// of the operation, whose names start with x-.
+	Extensions map[string]interface{} `json:"-" yaml:"-"`
 }
 
+// operation is an alias of Operation
+// without its marshaling methods.
+type operation Operation
+
 // A workaround for missing omitnil functionality.
 // Explicitely omit the Security field from marshaling when it is nil, but not when empty.
 type operationNilOmitted struct {
-	Tags         []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
-	Summary      string            `json:"summary,omitempty" yaml:"summary,omitempty"`
-	Description  string            `json:"description,omitempty" yaml:"description,omitempty"`
-	ID           string            `json:"operationId,omitempty" yaml:"operationId,omitempty"`
-	Parameters   []*ParameterOrRef `json:"parameters,omitempty" yaml:"parameters,omitempty"`
-	RequestBody  *RequestBody      `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
-	Responses    Responses         `json:"responses,omitempty" yaml:"responses,omitempty"`
-	Deprecated   bool              `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
-	Servers      []*Server         `json:"servers,omitempty" yaml:"servers,omitempty"`
-	XCodeSamples []*XCodeSample    `json:"x-codeSamples,omitempty" yaml:"x-codeSamples,omitempty"`
-	XInternal    bool              `json:"x-internal,omitempty" yaml:"x-internal,omitempty"`
+	Tags         []string               `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Summary      string                 `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description  string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	ExternalDocs *ExternalDocumentation `json:"externalDocs,omitempty" yaml:"externalDocs,omitempty"`
+	ID           string                 `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Parameters   []*ParameterOrRef      `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody  *RequestBody           `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses    Responses              `json:"responses,omitempty" yaml:"responses,omitempty"`
+	Callbacks    map[string]*Callback   `json:"callbacks,omitempty" yaml:"callbacks,omitempty"`
+	Deprecated   bool                   `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Servers      []*Server              `json:"servers,omitempty" yaml:"servers,omitempty"`
+	XCodeSamples []*XCodeSample         `json:"x-codeSamples,omitempty" yaml:"x-codeSamples,omitempty"`
+	XInternal    bool                   `json:"x-internal,omitempty" yaml:"x-internal,omitempty"`
 }
 
 // MarshalYAML implements yaml.Marshaler for Operation.
 // Needed to marshall empty but non-null SecurityRequirements.
 func (o *Operation) MarshalYAML() (interface{}, error) {
 	if o.Security == nil {
-		return omitOperationNilFields(o), nil
+		return marshalYAMLWithExtensions(omitOperationNilFields(o), o.Extensions)
 	}
-	return o, nil
+	return marshalYAMLWithExtensions((*operation)(o), o.Extensions)
 }
 
 // MarshalJSON excludes empty but non-null SecurityRequirements.
 func (o *Operation) MarshalJSON() ([]byte, error) {
 	if o.Security == nil {
-		return json.Marshal(omitOperationNilFields(o))
+		return marshalJSONWithExtensions(omitOperationNilFields(o), o.Extensions)
 	}
-	return json.Marshal(*o)
+	return marshalJSONWithExtensions((*operation)(o), o.Extensions)
 }
 
 func omitOperationNilFields(o *Operation) *operationNilOmitted {
@@ -240,10 +602,12 @@ func omitOperationNilFields(o *Operation) *operationNilOmitted {
 		Tags:         o.Tags,
 		Summary:      o.Summary,
 		Description:  o.Description,
+		ExternalDocs: o.ExternalDocs,
 		ID:           o.ID,
 		Parameters:   o.Parameters,
 		RequestBody:  o.RequestBody,
 		Responses:    o.Responses,
+		Callbacks:    o.Callbacks,
 		Deprecated:   o.Deprecated,
 		Servers:      o.Servers,
 		XCodeSamples: o.XCodeSamples,
@@ -251,6 +615,12 @@ func omitOperationNilFields(o *Operation) *operationNilOmitted {
 	}
 }
 
+// Callback represents a set of requests that may be initiated
+// by the API provider. It maps a runtime expression, evaluated
+// at runtime to identify the URL of the callback, to the path
+// item that describes the request and the expected responses.
+type Callback map[string]*PathItem
+
 // Responses represents a container for the expected responses
 // of an opration. It maps a HTTP response code to the expected
 // response.
@@ -276,6 +646,20 @@ type Response struct {
 	Description string                     `json:"description,omitempty" yaml:"description,omitempty"`
 	Headers     map[string]*HeaderOrRef    `json:"headers,omitempty" yaml:"headers,omitempty"`
 	Content     map[string]*MediaTypeOrRef `json:"content,omitempty" yaml:"content,omitempty"`
+	Links       map[string]*Link           `json:"links,omitempty" yaml:"links,omitempty"`
+}
+
+// Link represents a possible design-time link for a
+// response. The parameters are runtime expressions, such
+// as $response.body#/id, evaluated with the response to
+// call the linked operation.
+type Link struct {
+	OperationRef string                 `json:"operationRef,omitempty" yaml:"operationRef,omitempty"`
+	OperationID  string                 `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Parameters   map[string]interface{} `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody  interface{}            `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Description  string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	Server       *Server                `json:"server,omitempty" yaml:"server,omitempty"`
 }
 
 // HeaderOrRef represents a Header that can be inlined
@@ -300,6 +684,7 @@ type Header struct {
 	Deprecated      bool         `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
 	AllowEmptyValue bool         `json:"allowEmptyValue,omitempty" yaml:"allowEmptyValue,omitempty"`
 	Schema          *SchemaOrRef `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example         interface{}  `json:"example,omitempty" yaml:"example,omitempty"`
 }
 
 // MediaTypeOrRef represents a MediaType that can be inlined
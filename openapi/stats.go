@@ -0,0 +1,67 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// SpecStats represents the metrics of a specification.
+type SpecStats struct {
+	Paths      int
+	Operations int
+	Schemas    int
+	// OperationsWithoutDescription is the number
+	// of operations that have no description.
+	OperationsWithoutDescription int
+	// OperationsWithout4xx is the number of operations
+	// that have no response with a 4xx code or range.
+	OperationsWithout4xx int
+	// MissingDescriptions lists the sorted IDs of
+	// the operations that have no description. The
+	// operations without ID, such as the ones of a
+	// merged spec, are listed as "METHOD path".
+	MissingDescriptions []string
+}
+
+// Stats returns the metrics of the specification
+// returned by API, which is not modified.
+func (g *Generator) Stats() SpecStats {
+	api := g.API()
+
+	stats := SpecStats{
+		Paths:   len(api.Paths),
+		Schemas: len(api.Components.Schemas),
+	}
+	for path, item := range api.Paths {
+		if item == nil {
+			continue
+		}
+		for method, op := range item.operationsByMethod() {
+			stats.addOperation(method, path, op)
+		}
+	}
+	sort.Strings(stats.MissingDescriptions)
+
+	return stats
+}
+
+// addOperation adds the metrics of the operation
+// op, registered for the method and the path.
+func (s *SpecStats) addOperation(method, path string, op *Operation) {
+	s.Operations++
+
+	if op.Description == "" {
+		id := op.ID
+		if id == "" {
+			id = method + " " + path
+		}
+		s.OperationsWithoutDescription++
+		s.MissingDescriptions = append(s.MissingDescriptions, id)
+	}
+	for code := range op.Responses {
+		if strings.HasPrefix(code, "4") {
+			return
+		}
+	}
+	s.OperationsWithout4xx++
+}
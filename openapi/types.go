@@ -1,6 +1,8 @@
 package openapi
 
 import (
+	"database/sql"
+	"encoding"
 	"fmt"
 	"mime/multipart"
 	"net"
@@ -15,12 +17,16 @@ import (
 var (
 	tofDataType = reflect.TypeOf((*DataType)(nil)).Elem()
 	tofNullable = reflect.TypeOf((*Nullable)(nil)).Elem()
+	tofVariants = reflect.TypeOf((*Variants)(nil)).Elem()
+
+	tofTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
 
 	// Native.
 	tofTime           = reflect.TypeOf(time.Time{})
 	tofDuration       = reflect.TypeOf(time.Duration(0))
 	tofByteSlice      = reflect.TypeOf([]byte{})
 	tofNetIP          = reflect.TypeOf(net.IP{})
+	tofNetIPNet       = reflect.TypeOf(net.IPNet{})
 	tofNetURL         = reflect.TypeOf(url.URL{})
 	tofEmptyInterface = reflect.TypeOf(new(interface{})).Elem()
 	tofFileHeader     = reflect.TypeOf(multipart.FileHeader{})
@@ -29,6 +35,20 @@ var (
 	tofUUID = reflect.TypeOf(uuid.UUID{})
 )
 
+// sqlNullTypes maps the nullable types of the
+// database/sql package to the data type of
+// their value.
+var sqlNullTypes = map[reflect.Type]InternalDataType{
+	reflect.TypeOf(sql.NullString{}):  TypeString,
+	reflect.TypeOf(sql.NullInt64{}):   TypeLong,
+	reflect.TypeOf(sql.NullInt32{}):   TypeInteger,
+	reflect.TypeOf(sql.NullInt16{}):   TypeInteger,
+	reflect.TypeOf(sql.NullByte{}):    TypeInteger,
+	reflect.TypeOf(sql.NullFloat64{}): TypeDouble,
+	reflect.TypeOf(sql.NullBool{}):    TypeBoolean,
+	reflect.TypeOf(sql.NullTime{}):    TypeDateTime,
+}
+
 var _ DataType = (*InternalDataType)(nil)
 var _ DataType = (*OverridedDataType)(nil)
 
@@ -38,6 +58,13 @@ type Typer interface {
 	TypeName() string
 }
 
+// SchemaDescriber is the interface implemented by
+// the types that can describe the purpose of their
+// component schema.
+type SchemaDescriber interface {
+	SchemaDescription() string
+}
+
 // DataType is the interface implemented by types
 // that can describe their OAS3 data type and format.
 type DataType interface {
@@ -45,6 +72,14 @@ type DataType interface {
 	Format() string
 }
 
+// ArrayDataType is the interface implemented by the
+// types that implement DataType with the array type,
+// to describe the schema of their items.
+type ArrayDataType interface {
+	DataType
+	Items() *Schema
+}
+
 // Exampler is the interface implemented by custom types
 // that can parse example values.
 type Exampler interface {
@@ -57,6 +92,13 @@ type Nullable interface {
 	Nullable() bool
 }
 
+// Variants is the interface implemented by the types
+// that are serialized as any of several other types,
+// such as the unions with a custom JSON marshaler.
+type Variants interface {
+	Variants() []interface{}
+}
+
 // InternalDataType represents an internal type.
 type InternalDataType int
 
@@ -73,6 +115,13 @@ func (dt *OverridedDataType) Format() string { return dt.format }
 // Type implements DataType for OverridedDataType.
 func (dt *OverridedDataType) Type() string { return dt.typ }
 
+// EnumValue represents a named value of an enum type,
+// such as a constant declared for this type.
+type EnumValue struct {
+	Value interface{}
+	Name  string
+}
+
 // Type constants.
 const (
 	TypeInteger InternalDataType = iota
@@ -87,6 +136,7 @@ const (
 	TypeDateTime
 	TypeDuration
 	TypeIP
+	TypeCIDR
 	TypeURL
 	TypePassword
 	TypeAny
@@ -152,6 +202,8 @@ func DataTypeFromType(t reflect.Type) DataType {
 		return TypeByte
 	case tofNetIP:
 		return TypeIP
+	case tofNetIPNet:
+		return TypeCIDR
 	case tofNetURL:
 		return TypeURL
 	case tofEmptyInterface:
@@ -159,10 +211,18 @@ func DataTypeFromType(t reflect.Type) DataType {
 	case tofFileHeader:
 		return TypeFile
 	}
+	if dt, ok := sqlNullTypes[t]; ok {
+		return dt
+	}
 	// Treat imported types.
 	if dt := isImportedType(t); dt != nil {
 		return dt
 	}
+	// The types that marshal to text are
+	// serialized as strings.
+	if isTextMarshaler(t) {
+		return TypeString
+	}
 	// Switch over primitive types.
 	switch t.Kind() {
 	case reflect.Int64, reflect.Uint64:
@@ -193,9 +253,23 @@ func isImportedType(t reflect.Type) DataType {
 	if t == tofUUID {
 		return TypeUUID
 	}
+	// github.com/google/uuid, detected by the path
+	// of its package to avoid importing it.
+	if t.PkgPath() == "github.com/google/uuid" && t.Name() == "UUID" {
+		return TypeUUID
+	}
 	return nil
 }
 
+// isTextMarshaler returns whether the type t, or a
+// pointer to t, implements encoding.TextMarshaler.
+func isTextMarshaler(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface || t.Kind() == reflect.Ptr {
+		return false
+	}
+	return t.Implements(tofTextMarshaler) || reflect.PtrTo(t).Implements(tofTextMarshaler)
+}
+
 // stringToType converts val to t's type and return the new value.
 func stringToType(val string, t reflect.Type) (interface{}, error) {
 	// Compare type to know Golang types.
@@ -244,6 +318,7 @@ var datatypes = [...]string{
 	TypeDateTime:    "DateTime",
 	TypeDuration:    "Duration",
 	TypeIP:          "IP Address",
+	TypeCIDR:        "CIDR Notation",
 	TypeURL:         "URL (Uniform Resource Locator)",
 	TypePassword:    "Password",
 	TypeUnsupported: "Unsupported",
@@ -265,6 +340,7 @@ var types = [...]string{
 	TypeDateTime: "string",
 	TypeDuration: "string",
 	TypeIP:       "string",
+	TypeCIDR:     "string",
 	TypeURL:      "string",
 	TypePassword: "string",
 	TypeComplex:  "string",
@@ -285,6 +361,7 @@ var formats = [...]string{
 	TypeDateTime: "date-time",
 	TypeDuration: "duration",
 	TypeIP:       "ip",
+	TypeCIDR:     "cidr",
 	TypeURL:      "url",
 	TypePassword: "password",
 	TypeComplex:  "",
@@ -1,6 +1,7 @@
 package openapi
 
 import (
+	"database/sql"
 	"net"
 	"net/url"
 	"reflect"
@@ -8,7 +9,9 @@ import (
 	"time"
 	"unsafe"
 
+	"github.com/ccfish86/gadgeto/tonic"
 	"github.com/gofrs/uuid"
+	guuid "github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -31,6 +34,7 @@ func TestInternalDataType(t *testing.T) {
 		TypeDateTime:    {"string", "date-time"},
 		TypeDuration:    {"string", "duration"},
 		TypeIP:          {"string", "ip"},
+		TypeCIDR:        {"string", "cidr"},
 		TypeURL:         {"string", "url"},
 		TypePassword:    {"string", "password"},
 		TypeComplex:     {"string", ""},
@@ -95,6 +99,7 @@ func TestComplexGoTypeToDataType(t *testing.T) {
 		rt(5 * time.Second):          TypeDuration,
 		rt(url.URL{}):                TypeURL,
 		rt(net.IP{}):                 TypeIP,
+		rt(net.IPNet{}):              TypeCIDR,
 		rt(struct{}{}):               TypeComplex,
 		rt([]string{}):               TypeComplex,
 		rt([6]string{}):              TypeComplex,
@@ -131,6 +136,7 @@ func TestInternalDataTypeStringer(t *testing.T) {
 		TypeDateTime,
 		TypeDuration,
 		TypeIP,
+		TypeCIDR,
 		TypeURL,
 		TypePassword,
 		TypeComplex,
@@ -172,6 +178,45 @@ func TestCustomDataType(t *testing.T) {
 	}
 }
 
+// Coordinates is a custom type serialized as
+// an array of two numbers, [lat,lng].
+type Coordinates struct {
+	Lat, Lng float64
+}
+
+func (Coordinates) Format() string { return "" }
+func (Coordinates) Type() string   { return "array" }
+func (Coordinates) Items() *Schema { return &Schema{Type: "number", Format: "double"} }
+
+// TestCustomArrayDataType tests that a custom type that
+// implements the ArrayDataType interface describes the
+// schema of its items.
+func TestCustomArrayDataType(t *testing.T) {
+	g, err := NewGenerator(genConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type T struct {
+		Position Coordinates `json:"position"`
+	}
+	sor := g.newSchemaFromType(reflect.TypeOf(Coordinates{}), tonic.MediaType())
+	if assert.NotNil(t, sor) && assert.NotNil(t, sor.Schema) {
+		assert.Equal(t, "array", sor.Schema.Type)
+		if assert.NotNil(t, sor.Schema.Items) {
+			assert.Equal(t, &Schema{Type: "number", Format: "double"}, sor.Schema.Items.Schema)
+		}
+	}
+	sor = g.newSchemaFromType(reflect.TypeOf(T{}), tonic.MediaType())
+	schema := g.resolveSchema(sor)
+	if assert.NotNil(t, schema) {
+		position := schema.Properties["position"]
+		if assert.NotNil(t, position) && assert.NotNil(t, position.Items) {
+			assert.Equal(t, "number", position.Items.Type)
+		}
+	}
+	assert.Empty(t, g.Errors())
+}
+
 // TestStringToTimeType tests that a string can be
 // converted to the type of a time.Time.
 func TestStringToTimeType(t *testing.T) {
@@ -189,6 +234,32 @@ func TestStringToTimeType(t *testing.T) {
 	}
 }
 
+// TestStringToPointerType tests that a string can be
+// converted to the type of the value of a pointer.
+func TestStringToPointerType(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	v, err := stringToType(now.Format(time.RFC3339), rt(&now))
+	if err != nil {
+		t.Error(err)
+	}
+	vv, ok := v.(time.Time)
+	if !ok {
+		t.Errorf("expected converted value to be of type %T, got %T", now, v)
+	}
+	if !vv.Equal(now) {
+		t.Errorf("expected time to equal %s, got %s", now.String(), vv.String())
+	}
+	i := new(int)
+	v, err = stringToType("42", rt(&i))
+	if err != nil {
+		t.Error(err)
+	}
+	if v != int64(42) {
+		t.Errorf("expected converted value to be 42, got %T(%v)", v, v)
+	}
+}
+
 // TestStringToDurationType tests that a string can be
 // converted to the type of a time.Duration.
 func TestStringToDurationType(t *testing.T) {
@@ -253,4 +324,105 @@ func TestImportedTypes(t *testing.T) {
 	dt := DataTypeFromType(rt(uuid))
 	assert.Equal(t, "string", dt.Type())
 	assert.Equal(t, "uuid", dt.Format())
+
+	// github.com/google/uuid
+	for _, typ := range []reflect.Type{rt(guuid.UUID{}), rt(&guuid.UUID{})} {
+		dt = DataTypeFromType(typ)
+		assert.Equal(t, "string", dt.Type())
+		assert.Equal(t, "uuid", dt.Format())
+	}
+	// Imported types in slices and maps.
+	g := gen(t)
+	for _, typ := range []reflect.Type{rt([]guuid.UUID{}), rt(map[string]*guuid.UUID{})} {
+		sor := g.buildSchemaRecursive(typ, tonic.MediaType())
+		s := sor.Schema.Items
+		if s == nil {
+			s = sor.Schema.AdditionalProperties
+		}
+		assert.Equal(t, "string", s.Schema.Type)
+		assert.Equal(t, "uuid", s.Schema.Format)
+	}
+	assert.Empty(t, g.Errors())
+}
+
+type (
+	textAmount  struct{ Value, Currency string }
+	textVersion struct{ Major, Minor int }
+	textColor   struct{ R, G, B uint8 }
+)
+
+func (textAmount) MarshalText() ([]byte, error)   { return nil, nil }
+func (*textVersion) MarshalText() ([]byte, error) { return nil, nil }
+func (*textColor) MarshalText() ([]byte, error)   { return nil, nil }
+func (textColor) Type() string                    { return "string" }
+func (textColor) Format() string                  { return "color" }
+
+// TestTextMarshalerTypes tests that the types that
+// implement encoding.TextMarshaler are described
+// as strings, unless they describe their data type.
+func TestTextMarshalerTypes(t *testing.T) {
+	for _, typ := range []reflect.Type{
+		rt(textAmount{}),
+		rt(&textAmount{}),
+		rt(textVersion{}),
+		rt(&textVersion{}),
+	} {
+		dt := DataTypeFromType(typ)
+		assert.Equal(t, TypeString, dt, typ.String())
+	}
+	dt := DataTypeFromType(rt(textColor{}))
+	assert.Equal(t, "string", dt.Type())
+	assert.Equal(t, "color", dt.Format())
+
+	type T struct {
+		Amount   textAmount     `json:"amount"`
+		Versions []*textVersion `json:"versions"`
+		Colors   []textColor    `json:"colors"`
+	}
+	g := gen(t)
+	err := g.OverrideDataType(rt(textAmount{}), "string", "amount")
+	assert.Nil(t, err)
+
+	s := g.resolveSchema(g.newSchemaFromType(rt(T{}), tonic.MediaType()))
+	assert.Empty(t, g.Errors())
+
+	assert.Equal(t, &Schema{Type: "string", Format: "amount"}, s.Properties["amount"].Schema)
+	assert.Equal(t, &Schema{Type: "string"}, s.Properties["versions"].Schema.Items.Schema)
+	assert.Equal(t, &Schema{Type: "string", Format: "color"}, s.Properties["colors"].Schema.Items.Schema)
+
+	for _, name := range []string{"TextAmount", "TextVersion", "TextColor"} {
+		assert.NotContains(t, g.API().Components.Schemas, name)
+	}
+}
+
+// TestSQLNullTypes tests that the nullable types of
+// the database/sql package are described as nullable
+// schemas of the type of their value.
+func TestSQLNullTypes(t *testing.T) {
+	type T struct {
+		S  sql.NullString             `json:"s"`
+		I  sql.NullInt64              `json:"i"`
+		B  *sql.NullBool              `json:"b"`
+		T  sql.NullTime               `json:"t"`
+		TS []sql.NullTime             `json:"ts"`
+		F  map[string]sql.NullFloat64 `json:"f"`
+	}
+	g := gen(t)
+
+	s := g.resolveSchema(g.newSchemaFromType(rt(T{}), tonic.MediaType()))
+	assert.Empty(t, g.Errors())
+
+	for name, expected := range map[string]*Schema{
+		"s": {Type: "string", Nullable: true},
+		"i": {Type: "integer", Format: "int64", Nullable: true},
+		"b": {Type: "boolean", Nullable: true},
+		"t": {Type: "string", Format: "date-time", Nullable: true},
+	} {
+		assert.Equal(t, expected, s.Properties[name].Schema, name)
+	}
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time", Nullable: true}, s.Properties["ts"].Schema.Items.Schema)
+	assert.Equal(t, &Schema{Type: "number", Format: "double", Nullable: true}, s.Properties["f"].Schema.AdditionalProperties.Schema)
+
+	assert.NotContains(t, g.API().Components.Schemas, "NullString")
+	assert.NotContains(t, g.API().Components.Schemas, "NullTime")
 }
@@ -0,0 +1,196 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	tsInvalidIdentRe = regexp.MustCompile(`[^A-Za-z0-9_$]`)
+	tsPropertyNameRe = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+)
+
+// ExportTypeScript writes the TypeScript declarations
+// of the component schemas of the specification to w.
+// The object schemas are declared as interfaces, and
+// the other schemas as type aliases. The references to
+// the components use the names of their declarations.
+func (g *Generator) ExportTypeScript(w io.Writer) error {
+	schemas := g.API().Components.Schemas
+
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		sor := schemas[name]
+		if sor == nil {
+			continue
+		}
+		s := g.resolveSchema(sor)
+		if s != nil && sor.Schema != nil {
+			writeTSComment(&b, s, "")
+		}
+		if s != nil && sor.Schema != nil && isTSInterface(s) {
+			fmt.Fprintf(&b, "export interface %s ", tsIdentifier(name))
+			writeTSObject(&b, s, "")
+			b.WriteString("\n")
+		} else {
+			fmt.Fprintf(&b, "export type %s = %s;\n", tsIdentifier(name), tsType(sor, ""))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// isTSInterface returns whether the schema can
+// be declared with a TypeScript interface.
+func isTSInterface(s *Schema) bool {
+	return s.Type == "object" && len(s.Properties) != 0 &&
+		s.AdditionalProperties == nil && !s.Nullable && s.Enum == nil
+}
+
+// tsType returns the TypeScript type of a schema,
+// indented with the given prefix if it spans over
+// several lines.
+func tsType(sor *SchemaOrRef, indent string) string {
+	if sor == nil {
+		return "unknown"
+	}
+	if sor.Reference != nil {
+		return tsIdentifier(strings.TrimPrefix(sor.Ref, componentsSchemaPath))
+	}
+	s := sor.Schema
+	if s == nil {
+		return "unknown"
+	}
+	var t string
+
+	switch {
+	case len(s.Enum) != 0:
+		literals := make([]string, 0, len(s.Enum))
+		for _, v := range s.Enum {
+			b, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+			literals = append(literals, string(b))
+		}
+		t = strings.Join(literals, " | ")
+	case len(s.OneOf) != 0:
+		t = tsTypes(s.OneOf, " | ", indent)
+	case len(s.AnyOf) != 0:
+		t = tsTypes(s.AnyOf, " | ", indent)
+	case len(s.AllOf) != 0:
+		t = tsTypes(s.AllOf, " & ", indent)
+	default:
+		switch s.Type {
+		case "string":
+			t = "string"
+		case "integer", "number":
+			t = "number"
+		case "boolean":
+			t = "boolean"
+		case "array":
+			t = tsType(s.Items, indent)
+			if strings.ContainsAny(t, "|&") {
+				t = "(" + t + ")"
+			}
+			t += "[]"
+		case "object":
+			var b strings.Builder
+			switch {
+			case len(s.Properties) != 0:
+				writeTSObject(&b, s, indent)
+			case s.AdditionalProperties != nil:
+				fmt.Fprintf(&b, "{ [key: string]: %s }", tsType(s.AdditionalProperties, indent))
+			default:
+				b.WriteString("{ [key: string]: unknown }")
+			}
+			t = b.String()
+		default:
+			t = "unknown"
+		}
+	}
+	if s.Nullable && t != "unknown" {
+		t += " | null"
+	}
+	return t
+}
+
+// tsTypes returns the TypeScript types of the
+// schemas joined with the given separator.
+func tsTypes(sors []*SchemaOrRef, sep, indent string) string {
+	types := make([]string, 0, len(sors))
+	for _, sor := range sors {
+		types = append(types, tsType(sor, indent))
+	}
+	return strings.Join(types, sep)
+}
+
+// writeTSObject writes the properties of an object schema
+// as the members of a TypeScript object type. The members
+// that are not required are optional.
+func writeTSObject(b *strings.Builder, s *Schema, indent string) {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b.WriteString("{\n")
+	for _, name := range names {
+		sor := s.Properties[name]
+		if sor != nil && sor.Schema != nil {
+			writeTSComment(b, sor.Schema, indent+"  ")
+		}
+		pname := name
+		if !tsPropertyNameRe.MatchString(name) {
+			pname = fmt.Sprintf("%q", name)
+		}
+		if !containsString(s.Required, name) {
+			pname += "?"
+		}
+		fmt.Fprintf(b, "%s  %s: %s;\n", indent, pname, tsType(sor, indent+"  "))
+	}
+	b.WriteString(indent + "}")
+}
+
+// writeTSComment writes the description of the
+// schema and its deprecation as a JSDoc comment.
+func writeTSComment(b *strings.Builder, s *Schema, indent string) {
+	if s.Description == "" && !s.Deprecated {
+		return
+	}
+	b.WriteString(indent + "/**\n")
+	if s.Description != "" {
+		for _, l := range strings.Split(s.Description, "\n") {
+			fmt.Fprintf(b, "%s * %s\n", indent, strings.ReplaceAll(l, "*/", "*\\/"))
+		}
+	}
+	if s.Deprecated {
+		b.WriteString(indent + " * @deprecated\n")
+	}
+	b.WriteString(indent + " */\n")
+}
+
+// tsIdentifier returns a valid TypeScript identifier
+// for the name of a component schema.
+func tsIdentifier(name string) string {
+	id := tsInvalidIdentRe.ReplaceAllString(name, "_")
+	if id == "" || (id[0] >= '0' && id[0] <= '9') {
+		id = "_" + id
+	}
+	return id
+}
@@ -0,0 +1,240 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidateSpec checks the structure of the generated
+// specification and returns the problems that would
+// make it invalid for the tools that consume it, or
+// nil if none is found. It reports the references to
+// components that don't exist, the duplicate operation
+// IDs, the path parameters that are not declared by
+// the operations or not used by their paths, and the
+// required properties that are missing from schemas.
+func (g *Generator) ValidateSpec() []error {
+	api := g.API()
+
+	var errs []error
+	var (
+		refs    = make(map[string]bool)
+		schemas = make(map[*Schema]bool)
+	)
+	// Report unresolved references once.
+	checkRef := func(ref string) {
+		if !hasComponent(api, ref) && !refs[ref] {
+			errs = append(errs, fmt.Errorf("reference %s does not resolve to a component", ref))
+		}
+		refs[ref] = true
+	}
+	walkSchemaRefs(api, func(sor *SchemaOrRef, _ bool) bool {
+		if sor.Reference != nil {
+			checkRef(sor.Ref)
+			return false
+		}
+		s := sor.Schema
+		if s == nil || schemas[s] {
+			return false
+		}
+		schemas[s] = true
+
+		// The properties of a composed schema
+		// may be declared by its subschemas.
+		if len(s.AllOf) != 0 || len(s.OneOf) != 0 || len(s.AnyOf) != 0 {
+			return true
+		}
+		for _, name := range s.Required {
+			if _, ok := s.Properties[name]; !ok {
+				errs = append(errs, fmt.Errorf("required property %s is not defined in the schema", name))
+			}
+		}
+		return true
+	})
+	paths := make([]string, 0, len(api.Paths))
+	for path := range api.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	ids := make(map[string]string)
+	for _, path := range paths {
+		item := api.Paths[path]
+		if item == nil {
+			continue
+		}
+		for _, ref := range parameterRefs(item.Parameters) {
+			checkRef(ref)
+		}
+		methods := item.operationsByMethod()
+		for _, method := range []string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH", "TRACE"} {
+			op, ok := methods[method]
+			if !ok {
+				continue
+			}
+			if op.ID != "" {
+				if other, ok := ids[op.ID]; ok {
+					errs = append(errs, fmt.Errorf("operation ID %s of %s %s is already used by %s", op.ID, method, path, other))
+				} else {
+					ids[op.ID] = method + " " + path
+				}
+			}
+			errs = append(errs, validatePathParams(api, path, method, append(item.Parameters, op.Parameters...))...)
+
+			for _, ref := range operationRefs(op) {
+				checkRef(ref)
+			}
+		}
+	}
+	return errs
+}
+
+// operationRefs returns the sorted references used by the
+// parameters, the request body and the responses of the
+// operation op, including their headers and examples.
+func operationRefs(op *Operation) []string {
+	refs := parameterRefs(op.Parameters)
+
+	if rb := op.RequestBody; rb != nil {
+		if rb.Ref != "" {
+			refs = append(refs, rb.Ref)
+		}
+		for _, mt := range rb.Content {
+			if mt != nil {
+				refs = append(refs, exampleRefs(mt.Examples)...)
+			}
+		}
+	}
+	for _, r := range op.Responses {
+		if r == nil {
+			continue
+		}
+		if r.Reference != nil {
+			refs = append(refs, r.Ref)
+			continue
+		}
+		if r.Response == nil {
+			continue
+		}
+		for _, h := range r.Headers {
+			if h != nil && h.Reference != nil {
+				refs = append(refs, h.Ref)
+			}
+		}
+		for _, mt := range r.Content {
+			switch {
+			case mt == nil:
+			case mt.Reference != nil:
+				refs = append(refs, mt.Reference.Ref)
+			case mt.MediaType != nil:
+				refs = append(refs, exampleRefs(mt.Examples)...)
+			}
+		}
+	}
+	sort.Strings(refs)
+
+	return refs
+}
+
+// parameterRefs returns the references
+// of the parameters to the components.
+func parameterRefs(params []*ParameterOrRef) []string {
+	var refs []string
+	for _, p := range params {
+		if p != nil && p.Reference != nil {
+			refs = append(refs, p.Ref)
+		}
+	}
+	return refs
+}
+
+// exampleRefs returns the references
+// of the examples to the components.
+func exampleRefs(examples map[string]*ExampleOrRef) []string {
+	var refs []string
+	for _, e := range examples {
+		if e != nil && e.Reference != nil {
+			refs = append(refs, e.Reference.Ref)
+		}
+	}
+	return refs
+}
+
+// validatePathParams checks that the parameters declared
+// in the path template match the path parameters of the
+// operation, and returns an error for each mismatch.
+func validatePathParams(api *OpenAPI, path, method string, params []*ParameterOrRef) []error {
+	var errs []error
+
+	declared := make(map[string]bool)
+	for _, por := range params {
+		p := resolveParameterRef(api, por)
+		if p != nil && p.In == "path" {
+			declared[p.Name] = true
+		}
+	}
+	used := make(map[string]bool)
+	for _, m := range paramsInPathRe.FindAllStringSubmatch(path, -1) {
+		used[m[1]] = true
+		if !declared[m[1]] {
+			errs = append(errs, fmt.Errorf("path parameter %s of %s %s is not declared by the operation", m[1], method, path))
+		}
+	}
+	names := make([]string, 0, len(declared))
+	for name := range declared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !used[name] {
+			errs = append(errs, fmt.Errorf("path parameter %s of %s %s is not used in the path", name, method, path))
+		}
+	}
+	return errs
+}
+
+// resolveParameterRef returns the parameter, inlined
+// or referenced in the components of the spec.
+func resolveParameterRef(api *OpenAPI, por *ParameterOrRef) *Parameter {
+	if por == nil {
+		return nil
+	}
+	if por.Parameter != nil {
+		return por.Parameter
+	}
+	if por.Reference != nil && api.Components != nil {
+		name := strings.TrimPrefix(por.Ref, "#/components/parameters/")
+		if p, ok := api.Components.Parameters[name]; ok && p != nil {
+			return p.Parameter
+		}
+	}
+	return nil
+}
+
+// hasComponent returns whether the local
+// reference resolves to a component.
+func hasComponent(api *OpenAPI, ref string) bool {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 4 || parts[0] != "#" || parts[1] != "components" || api.Components == nil {
+		return false
+	}
+	var ok bool
+	switch name := parts[3]; parts[2] {
+	case "schemas":
+		_, ok = api.Components.Schemas[name]
+	case "responses":
+		_, ok = api.Components.Responses[name]
+	case "parameters":
+		_, ok = api.Components.Parameters[name]
+	case "examples":
+		_, ok = api.Components.Examples[name]
+	case "requestBodies":
+		_, ok = api.Components.RequestBodies[name]
+	case "headers":
+		_, ok = api.Components.Headers[name]
+	case "securitySchemes":
+		_, ok = api.Components.SecuritySchemes[name]
+	}
+	return ok
+}
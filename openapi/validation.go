@@ -1,52 +1,73 @@
 package openapi
 
 import (
+	"math"
 	"reflect"
 )
 
 // setSchemaMax sets the given maximum to the appropriate
-// schema field based on the given type.
-func setSchemaMax(schema *Schema, max int, t reflect.Type) {
+// schema field based on the given type. For numbers, an
+// exclusive maximum is described using the corresponding
+// modifier, while it is converted to an inclusive bound
+// for the lengths.
+func setSchemaMax(schema *Schema, max float64, exclusive bool, t reflect.Type) {
 	if isNumber(t) {
-		schema.Maximum = max
-	} else if isString(t) {
-		if max >= 0 {
-			schema.MaxLength = max
-		}
+		schema.Maximum = &max
+		schema.ExclusiveMaximum = exclusive
+		return
+	}
+	l, ok := length(max)
+	if !ok {
+		return
+	}
+	if exclusive {
+		l--
+	}
+	if l < 0 {
+		return
+	}
+	if isString(t) {
+		schema.MaxLength = l
 	} else if isMap(t) {
-		if max >= 0 {
-			schema.MaxProperties = max
-		}
-	} else if t.Kind() == reflect.Slice {
-		if max >= 0 {
-			schema.MaxItems = max
-		}
+		schema.MaxProperties = l
+	} else if isArray(t) {
+		schema.MaxItems = l
 	}
 }
 
 // setSchemaMin sets the given minimum to the appropriate
-// schema field based on the given type.
-func setSchemaMin(schema *Schema, min int, t reflect.Type) {
+// schema field based on the given type. For numbers, an
+// exclusive minimum is described using the corresponding
+// modifier, while it is converted to an inclusive bound
+// for the lengths.
+func setSchemaMin(schema *Schema, min float64, exclusive bool, t reflect.Type) {
 	if isNumber(t) {
-		schema.Minimum = min
-	} else if isString(t) {
-		if min >= 0 {
-			schema.MinLength = min
-		}
+		schema.Minimum = &min
+		schema.ExclusiveMinimum = exclusive
+		return
+	}
+	l, ok := length(min)
+	if !ok {
+		return
+	}
+	if exclusive {
+		l++
+	}
+	if l < 0 {
+		return
+	}
+	if isString(t) {
+		schema.MinLength = l
 	} else if isMap(t) {
-		if min >= 0 {
-			schema.MinProperties = min
-		}
-	} else if t.Kind() == reflect.Slice {
-		if min >= 0 {
-			schema.MinItems = min
-		}
+		schema.MinProperties = l
+	} else if isArray(t) {
+		schema.MinItems = l
 	}
 }
 
 // setSchemaEq sets the given equals value to the appropriate
 // schema field based on the given type.
-func setSchemaEq(schema *Schema, eq int, t reflect.Type) {
+func setSchemaEq(schema *Schema, eq float64, t reflect.Type) {
 	// For numbers and strings, equals tag would translate
 	// to the `const` property of the JSON Validation spec
 	// but OpenAPI doesn't support it.
@@ -58,9 +79,29 @@ func setSchemaEq(schema *Schema, eq int, t reflect.Type) {
 
 // setSchemaLen sets the given len to the appropriate
 // schema field based on the given type.
-func setSchemaLen(schema *Schema, len int, t reflect.Type) {
-	setSchemaMax(schema, len, t)
-	setSchemaMin(schema, len, t)
+func setSchemaLen(schema *Schema, len float64, t reflect.Type) {
+	setSchemaMax(schema, len, false, t)
+	setSchemaMin(schema, len, false, t)
+}
+
+// length returns the value v as a length, and
+// whether it is a valid integer value.
+func length(v float64) (int, bool) {
+	if v != math.Trunc(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return int(v), true
+}
+
+// setSchemaMultipleOf sets the number by which the value
+// of a numeric field must be divisible, and reports whether
+// it was set. Only positive numbers are valid divisors.
+func setSchemaMultipleOf(schema *Schema, n float64, t reflect.Type) bool {
+	if !isNumber(t) || n <= 0 {
+		return false
+	}
+	schema.MultipleOf = n
+	return true
 }
 
 // isString returns whether the given reflect type represents a string.
@@ -69,6 +110,12 @@ func isString(typ reflect.Type) bool { return typ.Kind() == reflect.String }
 // isMap returns whether the given reflect type represents a string.
 func isMap(typ reflect.Type) bool { return typ.Kind() == reflect.Map }
 
+// isArray returns whether the given reflect type
+// represents a slice or an array.
+func isArray(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array
+}
+
 // isNumber returns whether the given reflect type
 // represents a number.
 func isNumber(typ reflect.Type) bool {
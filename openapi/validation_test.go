@@ -3,6 +3,7 @@ package openapi
 import (
 	"encoding/json"
 	"io/ioutil"
+	"reflect"
 	"testing"
 
 	"github.com/ccfish86/gadgeto/tonic"
@@ -65,3 +66,197 @@ func TestSchemaValidation(t *testing.T) {
 		t.Error("expected json outputs to be equal")
 	}
 }
+
+// TestSchemaValidationBounds tests that the bounds
+// validators are translated to the schema keyword that
+// matches the kind of the field.
+func TestSchemaValidationBounds(t *testing.T) {
+	type T struct {
+		A int      `validate:"gte=1,lte=100"`
+		B int      `validate:"gt=0,lt=10"`
+		C float64  `validate:"min=0.5,max=99.9"`
+		D float32  `validate:"gt=-1.5"`
+		E string   `validate:"min=3,max=255"`
+		F string   `validate:"gt=3,lt=10"`
+		G []string `validate:"min=1,max=10"`
+		H *uint    `validate:"gte=0,unknown=5,foo"`
+		I string   `validate:"max=2.5"` // ignored, not a valid length
+	}
+	f := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		fname    string
+		expected *Schema
+	}{
+		{"A", &Schema{Minimum: f(1), Maximum: f(100)}},
+		{"B", &Schema{Minimum: f(0), ExclusiveMinimum: true, Maximum: f(10), ExclusiveMaximum: true}},
+		{"C", &Schema{Minimum: f(0.5), Maximum: f(99.9)}},
+		{"D", &Schema{Minimum: f(-1.5), ExclusiveMinimum: true}},
+		{"E", &Schema{MinLength: 3, MaxLength: 255}},
+		{"F", &Schema{MinLength: 4, MaxLength: 9}},
+		{"G", &Schema{MinItems: 1, MaxItems: 10}},
+		{"H", &Schema{Minimum: f(0)}},
+		{"I", &Schema{}},
+	}
+	typ := reflect.TypeOf(T{})
+
+	for _, tt := range tests {
+		t.Run(tt.fname, func(t *testing.T) {
+			g := gen(t)
+
+			sf, _ := typ.FieldByName(tt.fname)
+			schema := g.updateSchemaValidation(&Schema{}, sf)
+
+			assert.Equal(t, tt.expected, schema)
+			assert.Empty(t, g.Errors())
+		})
+	}
+}
+
+// TestSchemaValidationLen tests that the len validator
+// sets both bounds of the length of strings and arrays,
+// and that an invalid length is reported.
+func TestSchemaValidationLen(t *testing.T) {
+	type T struct {
+		A string  `validate:"len=6"`
+		B []int   `validate:"required,len=3"`
+		C *string `validate:"len=2"`
+		D string  `validate:"len=six"`
+	}
+	typ := reflect.TypeOf(T{})
+
+	tests := []struct {
+		fname    string
+		expected *Schema
+		err      bool
+	}{
+		{"A", &Schema{MinLength: 6, MaxLength: 6}, false},
+		{"B", &Schema{MinItems: 3, MaxItems: 3}, false},
+		{"C", &Schema{MinLength: 2, MaxLength: 2}, false},
+		{"D", &Schema{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fname, func(t *testing.T) {
+			g := gen(t)
+
+			sf, _ := typ.FieldByName(tt.fname)
+			schema := g.updateSchemaValidation(&Schema{}, sf)
+
+			assert.Equal(t, tt.expected, schema)
+			if tt.err {
+				if assert.Len(t, g.Errors(), 1) {
+					fe, ok := g.Errors()[0].(*FieldError)
+					if assert.True(t, ok) {
+						assert.Equal(t, "D", fe.Name)
+					}
+				}
+			} else {
+				assert.Empty(t, g.Errors())
+			}
+		})
+	}
+}
+
+// TestSchemaValidationOpenAPI31 tests that the exclusive
+// bounds are marshaled as numbers with OpenAPI 3.1.
+func TestSchemaValidationOpenAPI31(t *testing.T) {
+	min, max := 0.0, 10.0
+	schema := &Schema{
+		Type:             "integer",
+		Minimum:          &min,
+		ExclusiveMinimum: true,
+		Maximum:          &max,
+		v31:              true,
+	}
+	b, err := json.Marshal(schema)
+	if err != nil {
+		t.Error(err)
+	}
+	m, err := diffJSON(b, []byte(`{"type":"integer","exclusiveMinimum":0,"maximum":10}`))
+	if err != nil {
+		t.Error(err)
+	}
+	assert.True(t, m)
+}
+
+// TestSchemaValidationArrays tests that the validators
+// of a slice field describe the items of the array, and
+// that fixed-size arrays are bounded by their length.
+func TestSchemaValidationArrays(t *testing.T) {
+	type T struct {
+		A []string `validate:"min=1,max=10,unique"`
+		B [3]*X    `validate:"unique"`
+		C string   `validate:"min=1,max=10,unique"`
+	}
+	g := gen(t)
+	typ := reflect.TypeOf(T{})
+
+	sor := g.newSchemaFromStructField(typ.Field(0), false, "A", typ, tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.Equal(t, "array", sor.Type)
+	assert.Equal(t, 1, sor.MinItems)
+	assert.Equal(t, 10, sor.MaxItems)
+	assert.True(t, sor.UniqueItems)
+	assert.Zero(t, sor.MinLength)
+	assert.Zero(t, sor.MaxLength)
+
+	sor = g.newSchemaFromStructField(typ.Field(1), false, "B", typ, tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.Equal(t, "array", sor.Type)
+	assert.Equal(t, 3, sor.MinItems)
+	assert.Equal(t, 3, sor.MaxItems)
+	assert.True(t, sor.UniqueItems)
+
+	sor = g.newSchemaFromStructField(typ.Field(2), false, "C", typ, tonic.MediaType())
+	assert.NotNil(t, sor)
+	assert.Equal(t, 1, sor.MinLength)
+	assert.Equal(t, 10, sor.MaxLength)
+	assert.Zero(t, sor.MinItems)
+	assert.Zero(t, sor.MaxItems)
+	assert.False(t, sor.UniqueItems)
+}
+
+// TestSchemaMultipleOf tests that the multipleOf tag and
+// the multiple_of validator of a numeric field set the
+// multipleOf property of its schema.
+func TestSchemaMultipleOf(t *testing.T) {
+	type T struct {
+		A int      `multipleOf:"5"`
+		B *float64 `multipleOf:"0.01"`
+		C uint     `validate:"multiple_of=10"`
+		D string   `validate:"multiple_of=10"` // ignored, not a number
+		E string   `multipleOf:"5"`
+		F int      `multipleOf:"five"`
+		G int      `multipleOf:"-5"`
+	}
+	g := gen(t)
+	typ := reflect.TypeOf(T{})
+
+	tests := []struct {
+		fname      string
+		multipleOf float64
+	}{
+		{"A", 5},
+		{"B", 0.01},
+		{"C", 10},
+		{"D", 0},
+	}
+	for i, tt := range tests {
+		sor := g.newSchemaFromStructField(typ.Field(i), false, tt.fname, typ, tonic.MediaType())
+		assert.NotNil(t, sor)
+		assert.Equal(t, tt.multipleOf, sor.MultipleOf, tt.fname)
+	}
+	assert.Empty(t, g.Errors())
+
+	// Fields E, F and G have an invalid multipleOf tag.
+	for i, fname := range []string{"E", "F", "G"} {
+		sor := g.newSchemaFromStructField(typ.Field(4+i), false, fname, typ, tonic.MediaType())
+		assert.NotNil(t, sor)
+		assert.Zero(t, sor.MultipleOf, fname)
+		assert.Len(t, g.Errors(), i+1)
+
+		fe, ok := g.Errors()[i].(*FieldError)
+		assert.True(t, ok)
+		assert.Equal(t, fname, fe.Name)
+	}
+}
@@ -0,0 +1,139 @@
+package openapi
+
+// walkSchemas calls fn for every inlined schema of the
+// specification, including the nested schemas of objects,
+// arrays and maps. Each schema is visited only once, even
+// if it is shared by several parents.
+func walkSchemas(api *OpenAPI, fn func(*Schema)) {
+	seen := make(map[*Schema]struct{})
+
+	walkSchemaRefs(api, func(sor *SchemaOrRef, _ bool) bool {
+		s := sor.Schema
+		if s == nil {
+			return false
+		}
+		if _, ok := seen[s]; ok {
+			return false
+		}
+		seen[s] = struct{}{}
+		fn(s)
+
+		return true
+	})
+}
+
+// walkSchemaRefs calls fn for every schema or reference of
+// the specification, in depth-first order. The root parameter
+// indicates that sor is registered in the components schemas.
+// The nested schemas of sor are walked only if fn returns true.
+func walkSchemaRefs(api *OpenAPI, fn func(sor *SchemaOrRef, root bool) bool) {
+	walk := func(sor *SchemaOrRef, root bool) {
+		walkSchemaRef(sor, root, fn)
+	}
+	if api.Components != nil {
+		for _, sor := range api.Components.Schemas {
+			walk(sor, true)
+		}
+		for _, h := range api.Components.Headers {
+			if h != nil && h.Header != nil {
+				walk(h.Schema, false)
+			}
+		}
+		for _, p := range api.Components.Parameters {
+			if p != nil && p.Parameter != nil {
+				walk(p.Schema, false)
+			}
+		}
+	}
+	var walkItem func(item *PathItem)
+	walkItem = func(item *PathItem) {
+		if item == nil {
+			return
+		}
+		for _, p := range item.Parameters {
+			if p != nil && p.Parameter != nil {
+				walk(p.Schema, false)
+			}
+		}
+		for _, op := range item.operations() {
+			for _, p := range op.Parameters {
+				if p != nil && p.Parameter != nil {
+					walk(p.Schema, false)
+				}
+			}
+			if op.RequestBody != nil {
+				for _, mt := range op.RequestBody.Content {
+					if mt != nil {
+						walk(mt.Schema, false)
+					}
+				}
+			}
+			for _, r := range op.Responses {
+				if r == nil || r.Response == nil {
+					continue
+				}
+				for _, mt := range r.Content {
+					if mt != nil && mt.MediaType != nil {
+						walk(mt.Schema, false)
+					}
+				}
+				for _, h := range r.Headers {
+					if h != nil && h.Header != nil {
+						walk(h.Schema, false)
+					}
+				}
+			}
+			for _, cb := range op.Callbacks {
+				if cb != nil {
+					for _, item := range *cb {
+						walkItem(item)
+					}
+				}
+			}
+		}
+	}
+	for _, item := range api.Paths {
+		walkItem(item)
+	}
+}
+
+// walkSchemaRef calls fn for the schema or reference sor
+// and its nested schemas, in depth-first order.
+func walkSchemaRef(sor *SchemaOrRef, root bool, fn func(sor *SchemaOrRef, root bool) bool) {
+	if sor == nil || !fn(sor, root) || sor.Schema == nil {
+		return
+	}
+	s := sor.Schema
+
+	for _, sors := range [][]*SchemaOrRef{s.AllOf, s.OneOf, s.AnyOf} {
+		for _, sor := range sors {
+			walkSchemaRef(sor, false, fn)
+		}
+	}
+	walkSchemaRef(s.Items, false, fn)
+	walkSchemaRef(s.AdditionalProperties, false, fn)
+	for _, p := range s.Properties {
+		walkSchemaRef(p, false, fn)
+	}
+}
+
+// operations returns the non-nil operations of
+// the path item.
+func (pi *PathItem) operations() []*Operation {
+	var ops []*Operation
+	for _, op := range []*Operation{
+		pi.GET,
+		pi.PUT,
+		pi.POST,
+		pi.DELETE,
+		pi.OPTIONS,
+		pi.HEAD,
+		pi.PATCH,
+		pi.TRACE,
+	} {
+		if op != nil {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
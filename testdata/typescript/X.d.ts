@@ -0,0 +1,42 @@
+export interface V {
+  L?: number;
+}
+
+export interface XXX {
+  A: string;
+  B?: number | null;
+  /**
+   * @deprecated
+   */
+  C?: boolean;
+  D?: Y[];
+  E?: XXX[];
+  F?: XXX;
+  G?: Y;
+  H: number;
+  I?: string;
+  J?: number | null;
+  K: { [key: string]: Y } | null;
+  N?: {
+    Na?: string;
+    Nb?: string;
+    Nc?: string;
+  };
+  NI?: number | null;
+  NS?: string | null;
+  S?: number;
+  data?: V;
+  nnNnnN?: string;
+}
+
+export interface Y {
+  H: number;
+  I?: string;
+  J?: number | null;
+  K: { [key: string]: Y } | null;
+  N?: {
+    Na?: string;
+    Nb?: string;
+    Nc?: string;
+  };
+}
@@ -7,6 +7,9 @@ type SwaggerConfig struct {
 	OperationsSorter       string        `json:"operationsSorter"`
 	ValidatorUrl           string        `json:"validatorUrl"`
 	Urls                   *[]SwaggerUrl `json:"urls"`
+	// PrimaryName is the name of the spec
+	// selected by default among the urls.
+	PrimaryName string `json:"urls.primaryName,omitempty"`
 }
 
 type SwaggerUrl struct {
@@ -34,10 +34,7 @@ func AddUIHandler(ginEngine gin.IRoutes, path string, openApiJsonPath string) {
 	// init swagger-ui index.html
 	docIndex, _ := url.JoinPath(path, "/index.html")
 	ginEngine.GET(docIndex, func(c *gin.Context) {
-		c.Writer.WriteHeader(200)
-		c.Writer.Write(docHtml)
-		c.Writer.Header().Add("Accept", "text/html")
-		c.Writer.Flush()
+		c.Data(http.StatusOK, "text/html; charset=utf-8", docHtml)
 	})
 
 	// webjars
@@ -69,6 +66,8 @@ func AddUIHandler(ginEngine gin.IRoutes, path string, openApiJsonPath string) {
 }
 
 // AddUIGroupHandler adds handler that serves html for Swagger UI
+// with a selector of the specs of each group, the first group
+// being selected by default.
 func AddUIGroupHandler(ginEngine gin.IRoutes, path string, groups ...SwaggerUrl) {
 
 	if len(groups) == 0 {
@@ -78,16 +77,13 @@ func AddUIGroupHandler(ginEngine gin.IRoutes, path string, groups ...SwaggerUrl)
 	// for `v3/api-docs/swagger-config`, as springdoc
 	configPath, _ := url.JoinPath(path, "v3/api-docs/swagger-config")
 	ginEngine.GET(configPath, func(c *gin.Context) {
-		c.JSON(200, &SwaggerConfig{ConfigUrl: configPath, DisplayRequestDuration: true, OperationsSorter: "method", Urls: &groups})
+		c.JSON(200, &SwaggerConfig{ConfigUrl: configPath, DisplayRequestDuration: true, OperationsSorter: "method", Urls: &groups, PrimaryName: groups[0].Name})
 	})
 
 	// init swagger-ui index.html
 	docIndex, _ := url.JoinPath(path, "/index.html")
 	ginEngine.GET(docIndex, func(c *gin.Context) {
-		c.Writer.WriteHeader(200)
-		c.Writer.Write(docHtml)
-		c.Writer.Header().Add("Accept", "text/html")
-		c.Writer.Flush()
+		c.Data(http.StatusOK, "text/html; charset=utf-8", docHtml)
 	})
 
 	// webjars
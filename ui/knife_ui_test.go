@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAddUIGroupHandler tests that the page and the
+// assets of the UI are served from the embedded files,
+// with the content type matching their extension.
+func TestAddUIGroupHandler(t *testing.T) {
+	engine := gin.New()
+
+	AddUIGroupHandler(engine, "/doc", SwaggerUrl{
+		Name: "app",
+		Url:  "/app/openapi.json",
+	})
+	tests := []struct {
+		path        string
+		contentType string
+	}{
+		{"/doc/index.html", "text/html"},
+		{"/doc/webjars/js/app.062a7890.js", "javascript"},
+		{"/doc/webjars/css/app.7c14bd7b.css", "text/css"},
+		{"/doc/oauth/oauth2.html", "text/html"},
+		{"/doc/v3/api-docs/swagger-config", "application/json"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, tt.path)
+		assert.Contains(t, w.Header().Get("Content-Type"), tt.contentType, tt.path)
+		assert.NotZero(t, w.Body.Len(), tt.path)
+	}
+}
+
+// TestAddUIGroupHandlerSpecs tests that the config of
+// the UI lists the specs of all the groups, in order.
+func TestAddUIGroupHandlerSpecs(t *testing.T) {
+	engine := gin.New()
+
+	AddUIGroupHandler(engine, "/doc", SwaggerUrl{
+		Name: "APP",
+		Url:  "/app/openapi.json",
+	}, SwaggerUrl{
+		Name: "Admin",
+		Url:  "/admin/openapi.json",
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/doc/v3/api-docs/swagger-config", nil)
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var conf SwaggerConfig
+	if err := json.Unmarshal(w.Body.Bytes(), &conf); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "APP", conf.PrimaryName)
+	if assert.NotNil(t, conf.Urls) {
+		assert.Equal(t, []SwaggerUrl{
+			{Name: "APP", Url: "/app/openapi.json"},
+			{Name: "Admin", Url: "/admin/openapi.json"},
+		}, *conf.Urls)
+	}
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"embed"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rapiDocScriptURL is the location of the script
+// of the RapiDoc web component on the public CDN.
+const rapiDocScriptURL = "https://unpkg.com/rapidoc/dist/rapidoc-min.js"
+
+// rapiDocScript is the script of the RapiDoc web component
+// vendored in the embedded file system by go generate.
+const rapiDocScript = "rapidoc/rapidoc-min.js"
+
+//go:generate curl -sSfL -o rapidoc/rapidoc-min.js https://unpkg.com/rapidoc/dist/rapidoc-min.js
+
+//go:embed rapidoc
+var rapiDocFS embed.FS
+
+var rapiDocTemplate = template.Must(template.ParseFS(rapiDocFS, "rapidoc/index.html"))
+
+// rapiDocConfig represents the attributes of
+// the RapiDoc web component.
+type rapiDocConfig struct {
+	Title       string
+	SpecURL     string
+	ScriptURL   string
+	Theme       string
+	RenderStyle string
+
+	useCDN bool
+}
+
+// RapiDocOption represents an option of the RapiDoc UI.
+type RapiDocOption func(*rapiDocConfig)
+
+// RapiDocTheme sets the theme of the RapiDoc UI,
+// either "light" or "dark".
+func RapiDocTheme(theme string) RapiDocOption {
+	return func(c *rapiDocConfig) {
+		c.Theme = theme
+	}
+}
+
+// RapiDocRenderStyle sets the layout of the RapiDoc UI,
+// either "read", "view" or "focused".
+func RapiDocRenderStyle(style string) RapiDocOption {
+	return func(c *rapiDocConfig) {
+		c.RenderStyle = style
+	}
+}
+
+// RapiDocScriptURL sets the location of the script of the
+// RapiDoc web component, to use another copy than the one
+// embedded in the package.
+func RapiDocScriptURL(url string) RapiDocOption {
+	return func(c *rapiDocConfig) {
+		c.ScriptURL = url
+	}
+}
+
+// RapiDocUseCDN defines whether the script of the RapiDoc
+// web component is loaded from the public CDN instead of
+// the copy embedded in the package. Default to false.
+func RapiDocUseCDN(b bool) RapiDocOption {
+	return func(c *rapiDocConfig) {
+		c.useCDN = b
+	}
+}
+
+// AddRapiDocHandler adds handler that serves html for RapiDoc
+func AddRapiDocHandler(ginEngine gin.IRoutes, path string, spec SwaggerUrl, opts ...RapiDocOption) {
+	conf := &rapiDocConfig{
+		Title:       spec.Name,
+		SpecURL:     spec.Url,
+		Theme:       "light",
+		RenderStyle: "read",
+	}
+	for _, opt := range opts {
+		opt(conf)
+	}
+	// The embedded script is served from the route of
+	// the page, the CDN is used only if it is required
+	// or if the script was not vendored.
+	if conf.ScriptURL == "" && !conf.useCDN {
+		conf.ScriptURL, _ = addScriptHandler(ginEngine, path, rapiDocFS, rapiDocScript)
+	}
+	if conf.ScriptURL == "" {
+		conf.ScriptURL = rapiDocScriptURL
+	}
+	ginEngine.GET(path, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		if err := rapiDocTemplate.Execute(c.Writer, conf); err != nil {
+			_ = c.Error(err)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// TestAddRapiDocHandler tests that the RapiDoc page
+// refers to the specification and honors the options.
+func TestAddRapiDocHandler(t *testing.T) {
+	engine := gin.New()
+
+	AddRapiDocHandler(engine, "/rapidoc", SwaggerUrl{
+		Name: "Fruits Market",
+		Url:  "/openapi.json",
+	}, RapiDocTheme("dark"), RapiDocRenderStyle("view"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/rapidoc", nil)
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	assert.Contains(t, body, `spec-url="/openapi.json"`)
+	assert.Contains(t, body, `theme="dark"`)
+	assert.Contains(t, body, `render-style="view"`)
+	assert.Contains(t, body, "<title>Fruits Market</title>")
+
+	// The embedded script is served from the route
+	// of the page once it is vendored.
+	if _, err := rapiDocFS.ReadFile(rapiDocScript); err == nil {
+		assert.Contains(t, body, `src="/rapidoc/rapidoc-min.js"`)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/rapidoc/rapidoc-min.js", nil)
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/javascript; charset=utf-8", w.Header().Get("Content-Type"))
+	} else {
+		assert.Contains(t, body, rapiDocScriptURL)
+	}
+	// The CDN is used on demand.
+	AddRapiDocHandler(engine, "/rapidoc-cdn", SwaggerUrl{Url: "/openapi.json"}, RapiDocUseCDN(true))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/rapidoc-cdn", nil)
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), rapiDocScriptURL)
+}
+
+// TestAddScriptHandler tests that a script embedded
+// in the package is served from the route of a page.
+func TestAddScriptHandler(t *testing.T) {
+	engine := gin.New()
+
+	scriptURL, ok := addScriptHandler(engine, "/docs", rapiDocFS, "rapidoc/index.html")
+	assert.True(t, ok)
+	assert.Equal(t, "/docs/index.html", scriptURL)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", scriptURL, nil)
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/javascript; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<rapi-doc")
+
+	_, ok = addScriptHandler(engine, "/docs", rapiDocFS, "rapidoc/missing.js")
+	assert.False(t, ok)
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scalarScriptURL is the location of the standalone script
+// of the Scalar API reference on the public CDN.
+const scalarScriptURL = "https://cdn.jsdelivr.net/npm/@scalar/api-reference"
+
+// scalarScript is the standalone script of the Scalar API
+// reference vendored in the embedded file system by go generate.
+const scalarScript = "scalar/standalone.js"
+
+//go:generate curl -sSfL -o scalar/standalone.js https://cdn.jsdelivr.net/npm/@scalar/api-reference
+
+//go:embed scalar
+var scalarFS embed.FS
+
+var scalarTemplate = template.Must(template.ParseFS(scalarFS, "scalar/index.html"))
+
+// scalarConfig represents the attributes of
+// the Scalar API reference.
+type scalarConfig struct {
+	Title         string
+	SpecURL       string
+	ScriptURL     string
+	Configuration string
+
+	theme  string
+	useCDN bool
+}
+
+// ScalarOption represents an option of the Scalar UI.
+type ScalarOption func(*scalarConfig)
+
+// ScalarTheme sets the theme of the Scalar UI, such
+// as "default", "moon", "purple" or "solarized".
+func ScalarTheme(theme string) ScalarOption {
+	return func(c *scalarConfig) {
+		c.theme = theme
+	}
+}
+
+// ScalarScriptURL sets the location of the standalone
+// script of the Scalar API reference, to use another copy
+// than the one embedded in the package.
+func ScalarScriptURL(url string) ScalarOption {
+	return func(c *scalarConfig) {
+		c.ScriptURL = url
+	}
+}
+
+// ScalarUseCDN defines whether the standalone script of the
+// Scalar API reference is loaded from the public CDN instead
+// of the copy embedded in the package. Default to false.
+func ScalarUseCDN(b bool) ScalarOption {
+	return func(c *scalarConfig) {
+		c.useCDN = b
+	}
+}
+
+// AddScalarHandler adds handler that serves html for Scalar
+func AddScalarHandler(ginEngine gin.IRoutes, path string, spec SwaggerUrl, opts ...ScalarOption) {
+	conf := &scalarConfig{
+		Title:   spec.Name,
+		SpecURL: spec.Url,
+		theme:   "default",
+	}
+	for _, opt := range opts {
+		opt(conf)
+	}
+	// The embedded script is served from the route of
+	// the page, the CDN is used only if it is required
+	// or if the script was not vendored.
+	if conf.ScriptURL == "" && !conf.useCDN {
+		conf.ScriptURL, _ = addScriptHandler(ginEngine, path, scalarFS, scalarScript)
+	}
+	if conf.ScriptURL == "" {
+		conf.ScriptURL = scalarScriptURL
+	}
+	b, _ := json.Marshal(map[string]string{"theme": conf.theme})
+	conf.Configuration = string(b)
+
+	ginEngine.GET(path, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		if err := scalarTemplate.Execute(c.Writer, conf); err != nil {
+			_ = c.Error(err)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAddScalarHandler tests that the Scalar page
+// refers to the specification and honors the options.
+func TestAddScalarHandler(t *testing.T) {
+	engine := gin.New()
+
+	AddScalarHandler(engine, "/scalar", SwaggerUrl{
+		Name: "Fruits Market",
+		Url:  "/openapi.json",
+	}, ScalarTheme("moon"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/scalar", nil)
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	assert.Contains(t, body, `<script id="api-reference"`)
+	assert.Contains(t, body, `data-url="/openapi.json"`)
+	assert.Contains(t, body, `data-configuration="{&#34;theme&#34;:&#34;moon&#34;}"`)
+	assert.Contains(t, body, "<title>Fruits Market</title>")
+
+	// The embedded script is served from the route
+	// of the page once it is vendored.
+	if _, err := scalarFS.ReadFile(scalarScript); err == nil {
+		assert.Contains(t, body, `<script src="/scalar/standalone.js"></script>`)
+
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/scalar/standalone.js", nil)
+		engine.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/javascript; charset=utf-8", w.Header().Get("Content-Type"))
+	} else {
+		assert.Contains(t, body, `<script src="`+scalarScriptURL+`"></script>`)
+	}
+	// The CDN is used on demand.
+	AddScalarHandler(engine, "/scalar-cdn", SwaggerUrl{Url: "/openapi.json"}, ScalarUseCDN(true))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/scalar-cdn", nil)
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<script src="`+scalarScriptURL+`"></script>`)
+}
@@ -0,0 +1,26 @@
+package ui
+
+import (
+	"embed"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addScriptHandler adds handler that serves the script
+// vendored in the embedded file system at name, under the
+// route path, and returns the URL of the script. It returns
+// false if the script was not vendored with go generate.
+func addScriptHandler(ginEngine gin.IRoutes, routePath string, fsys embed.FS, name string) (string, bool) {
+	b, err := fsys.ReadFile(name)
+	if err != nil {
+		return "", false
+	}
+	scriptURL, _ := url.JoinPath(routePath, path.Base(name))
+	ginEngine.GET(scriptURL, func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/javascript; charset=utf-8", b)
+	})
+	return scriptURL, true
+}